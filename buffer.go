@@ -0,0 +1,157 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Read buffering for children, for WithBufferSize.
+
+import (
+	"io"
+	"sync"
+)
+
+// A child whose reads go through a buffer. Like a bufio.Reader, but it
+// can also Seek; a Seek that lands inside the buffer doesn't touch the
+// child.
+type bufferedChild struct {
+	child ReadCloseSeeker
+
+	// The buffer comes from the pool when the child is read, and goes
+	// back to it when Read has moved on to another child, or the child
+	// is closed, so that only the children being read have one.
+	pool *sync.Pool
+	bufp *[]byte
+	buf  []byte
+	size int
+
+	// buf[r:w] hasn't been read yet
+	r, w int
+
+	// The position of the child; buf[w] would be read from here
+	childPos int64
+
+	// An error from the child that came with bytes; it is returned
+	// once buf[r:w] has been read
+	err error
+}
+
+// A bufferedChild for a child that has ReadAt, so that it has ReadAt too
+type bufferedReaderAtChild struct {
+	*bufferedChild
+	readerAt io.ReaderAt
+}
+
+func (self bufferedReaderAtChild) ReadAt(p []byte, off int64) (int, error) {
+	return self.readerAt.ReadAt(p, off)
+}
+
+// A pool of buffers of size bytes
+func newBufferPool(size int) *sync.Pool {
+	return &sync.Pool{
+		New: func() interface{} {
+			buf := make([]byte, size)
+			return &buf
+		},
+	}
+}
+
+// Wrap child so that its reads are buffered, with buffers of size bytes
+// from pool
+func newBufferedChild(child ReadCloseSeeker, pool *sync.Pool, size int) ReadCloseSeeker {
+	buffered := &bufferedChild{
+		child: child,
+		pool:  pool,
+		size:  size,
+	}
+	if readerAt, ok := child.(io.ReaderAt); ok {
+		return bufferedReaderAtChild{buffered, readerAt}
+	}
+	return buffered
+}
+
+// The position that the next Read reads from
+func (self *bufferedChild) pos() int64 {
+	return self.childPos - int64(self.w-self.r)
+}
+
+func (self *bufferedChild) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if self.r == self.w {
+		if self.err != nil {
+			err := self.err
+			self.err = nil
+			return 0, err
+		}
+		if len(p) >= self.size {
+			// Large read; don't bother copying through the buffer
+			n, err := self.child.Read(p)
+			self.childPos += int64(n)
+			return n, err
+		}
+		if self.buf == nil {
+			self.bufp = self.pool.Get().(*[]byte)
+			self.buf = *self.bufp
+		}
+		n, err := self.child.Read(self.buf)
+		self.r = 0
+		self.w = n
+		self.childPos += int64(n)
+		if n == 0 {
+			return 0, err
+		}
+		self.err = err
+	}
+	n := copy(p, self.buf[self.r:self.w])
+	self.r += n
+	return n, nil
+}
+
+func (self *bufferedChild) Seek(offset int64, whence int) (int64, error) {
+	var target int64
+	switch whence {
+	case io.SeekStart:
+		target = offset
+	case io.SeekCurrent:
+		target = self.pos() + offset
+	default:
+		// Only the child knows where its end is
+		return self.seekChild(offset, whence)
+	}
+
+	// Still in the buffer?
+	bufStart := self.childPos - int64(self.w)
+	if target >= bufStart && target <= self.childPos {
+		self.r = int(target - bufStart)
+		return target, nil
+	}
+	return self.seekChild(target, io.SeekStart)
+}
+
+// Seek the child and drop what's in the buffer
+func (self *bufferedChild) seekChild(offset int64, whence int) (int64, error) {
+	self.r = 0
+	self.w = 0
+	self.err = nil
+	pos, err := self.child.Seek(offset, whence)
+	self.childPos = pos
+	return pos, err
+}
+
+// Give the buffer back to the pool, if nothing in it is left to read
+func (self *bufferedChild) release() {
+	if self.buf == nil || self.r != self.w {
+		return
+	}
+	self.pool.Put(self.bufp)
+	self.bufp = nil
+	self.buf = nil
+	self.r = 0
+	self.w = 0
+}
+
+func (self *bufferedChild) Close() error {
+	// What's left in the buffer won't be read now
+	self.r = self.w
+	self.release()
+	return self.child.Close()
+}
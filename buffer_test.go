@@ -0,0 +1,214 @@
+package multireadseeker
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+// A child that counts its Reads, and can take a while to do each one,
+// like a file on network storage
+type slowChild struct {
+	seekOnlyChild
+	latency   time.Duration
+	readCalls int
+}
+
+func newSlowChild(data []byte, latency time.Duration) *slowChild {
+	return &slowChild{
+		seekOnlyChild: seekOnlyChild{r: bytes.NewReader(data)},
+		latency:       latency,
+	}
+}
+
+func (self *slowChild) Read(p []byte) (int, error) {
+	self.readCalls++
+	time.Sleep(self.latency)
+	return self.seekOnlyChild.Read(p)
+}
+
+func (s *MySuite) TestBufferSize(c *C) {
+	child1 := newSlowChild([]byte("ABCDEFGH"), 0)
+	child2 := newSlowChild([]byte("IJKLMNOP"), 0)
+	mrseeker, err := NewWithOptions([]Option{WithBufferSize(4)}, child1, child2)
+	c.Assert(err, IsNil)
+
+	// One byte at a time, but the children are read 4 bytes at a time
+	buf := make([]byte, 1)
+	for i := 0; i < 6; i++ {
+		n, err := mrseeker.Read(buf)
+		c.Assert(err, IsNil)
+		c.Assert(n, Equals, 1)
+		c.Check(buf[0], Equals, "ABCDEF"[i])
+	}
+	c.Check(child1.readCalls, Equals, 2)
+
+	// Seeking back inside the buffer doesn't read the child again
+	_, err = mrseeker.Seek(-2, io.SeekCurrent)
+	c.Assert(err, IsNil)
+	buf = make([]byte, 3)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "EFG")
+	c.Check(child1.readCalls, Equals, 2)
+
+	// Seeking outside the buffer does
+	_, err = mrseeker.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	n, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "BCD")
+	c.Check(child1.readCalls, Equals, 3)
+
+	// ReadAt doesn't lose the Read position
+	n, err = mrseeker.ReadAt(buf, 7)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "HIJ")
+
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "EFGHIJKLMNOP")
+
+	// The children are closed through their buffers
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+	c.Check(child1.closeCalls, Equals, 1)
+	c.Check(child2.closeCalls, Equals, 1)
+}
+
+func (s *MySuite) TestBufferSizeReaderAt(c *C) {
+	names := s.writeDataFiles(c, "bufferreaderat", "ABCDEFGH")
+	fh, err := os.Open(names[0])
+	c.Assert(err, IsNil)
+	mrseeker, err := NewWithOptions([]Option{WithBufferSize(4)}, fh)
+	c.Assert(err, IsNil)
+	child, err := mrseeker.ChildAt(0)
+	c.Assert(err, IsNil)
+	_, ok := child.(io.ReaderAt)
+	c.Check(ok, Equals, true)
+
+	buf := make([]byte, 3)
+	n, err := mrseeker.ReadAt(buf, 4)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "EFG")
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestBufferChildError(c *C) {
+	// The child returns an error along with the bytes of a fill
+	errX := errors.New("transient")
+	child := newScriptedChild("ABCDEFGH")
+	child.failAt = 3
+	child.failErr = errX
+	child.failOnce = true
+	buffered := newBufferedChild(child, newBufferPool(4), 4)
+
+	// The error waits until the bytes that came with it are read
+	buf := make([]byte, 2)
+	n, err := buffered.Read(buf[:1])
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "A")
+	n, err = buffered.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "BC")
+	n, err = buffered.Read(buf)
+	c.Check(n, Equals, 0)
+	c.Check(err, Equals, errX)
+
+	// ... and is returned only once
+	data, err := ioutil.ReadAll(buffered)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "DEFGH")
+}
+
+func (s *MySuite) TestBufferPool(c *C) {
+	mrseeker, err := NewWithOptions([]Option{WithBufferSize(4)},
+		newSeekOnlyChild("ABC"), newSeekOnlyChild("DEF"))
+	c.Assert(err, IsNil)
+	buffered := func(i int) *bufferedChild {
+		child, err := mrseeker.ChildAt(i)
+		c.Assert(err, IsNil)
+		return child.(*bufferedChild)
+	}
+
+	// No buffer until a child is read
+	c.Check(buffered(0).buf, IsNil)
+	buf := make([]byte, 1)
+	_, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(buffered(0).buf, NotNil)
+	c.Check(buffered(1).buf, IsNil)
+
+	// The first child's buffer goes back when Read moves on
+	buf = make([]byte, 3)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "BCD")
+	c.Check(buffered(0).buf, IsNil)
+	c.Check(buffered(1).buf, NotNil)
+
+	_, err = mrseeker.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "BCDEF")
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+	c.Check(buffered(1).buf, IsNil)
+}
+
+// Read 1 MiB, 512 bytes at a time, from children that take 10µs per read
+func benchmarkBufferSize(c *C, opts []Option) {
+	data := make([]byte, 256*1024)
+	buf := make([]byte, 512)
+	c.SetBytes(4 * int64(len(data)))
+	for i := 0; i < c.N; i++ {
+		children := make([]ReadCloseSeeker, 4)
+		for j := range children {
+			children[j] = newSlowChild(data, 10*time.Microsecond)
+		}
+		mrseeker, err := NewWithOptions(opts, children...)
+		c.Assert(err, IsNil)
+		_, err = io.CopyBuffer(ioutil.Discard, struct{ io.Reader }{mrseeker}, buf)
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *MySuite) BenchmarkUnbuffered(c *C) {
+	benchmarkBufferSize(c, nil)
+}
+
+func (s *MySuite) BenchmarkBufferSize64K(c *C) {
+	benchmarkBufferSize(c, []Option{WithBufferSize(64 * 1024)})
+}
+
+// Small sequential Reads through buffered children; with -check.bmem,
+// this shows no allocations per Read once the buffers are in the pool
+func (s *MySuite) BenchmarkBufferedSmallReads(c *C) {
+	children := make([]ReadCloseSeeker, 100)
+	for i := range children {
+		children[i] = NopCloser(bytes.NewReader(make([]byte, 1000)))
+	}
+	mrseeker, err := NewWithOptions([]Option{WithBufferSize(4096)}, children...)
+	c.Assert(err, IsNil)
+	buf := make([]byte, 100)
+	c.SetBytes(int64(len(buf)))
+	c.ResetTimer()
+	for i := 0; i < c.N; i++ {
+		_, err = mrseeker.Read(buf)
+		if err == io.EOF {
+			_, err = mrseeker.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			c.Fatal(err)
+		}
+	}
+}
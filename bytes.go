@@ -0,0 +1,107 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Reading one byte, or one rune, at a time, for io.ByteScanner and
+// io.RuneScanner.
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf8"
+)
+
+// As with bufio, ReadByte gives up after this many Reads in a row that
+// return nothing and no error
+const maxConsecutiveEmptyReads = 100
+
+// ReadByte reads the next byte, as for encoding/binary, without
+// allocating a buffer. If a child keeps returning no bytes and no
+// error, it returns io.ErrNoProgress.
+func (self *MultiReadSeeker) ReadByte() (byte, error) {
+	for i := 0; i < maxConsecutiveEmptyReads; i++ {
+		n, err := self.Read(self.byteBuf[:])
+		if n == 1 {
+			return self.byteBuf[0], nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return 0, io.ErrNoProgress
+}
+
+// UnreadByte moves the position back one byte, into the previous
+// child if it is at the start of a child. At position 0, it returns
+// bufio.ErrInvalidUnreadByte.
+func (self *MultiReadSeeker) UnreadByte() error {
+	if self.currentSuperPos <= 0 {
+		return bufio.ErrInvalidUnreadByte
+	}
+	_, err := self.Seek(-1, io.SeekCurrent)
+	return err
+}
+
+// ReadRune reads the next UTF-8 encoded rune, even if its bytes are
+// in more than one child. As with bufio.Reader, if the bytes aren't
+// valid UTF-8, it returns utf8.RuneError with a size of 1, and only
+// that one byte is read.
+func (self *MultiReadSeeker) ReadRune() (rune, int, error) {
+	start := self.currentSuperPos
+	b, err := self.ReadByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	if b < utf8.RuneSelf {
+		self.lastRuneSize = 1
+		return rune(b), 1, nil
+	}
+
+	var size int
+	switch {
+	case b&0xe0 == 0xc0:
+		size = 2
+	case b&0xf0 == 0xe0:
+		size = 3
+	case b&0xf8 == 0xf0:
+		size = 4
+	default:
+		self.lastRuneSize = 1
+		return utf8.RuneError, 1, nil
+	}
+	buf := self.runeBuf[:size]
+	buf[0] = b
+	n := 1
+	for ; n < size; n++ {
+		b, err = self.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		buf[n] = b
+	}
+	r, runeSize := utf8.DecodeRune(buf[:n])
+	if runeSize != size {
+		// Not valid; go back to just after the first byte
+		_, err = self.Seek(start+1, io.SeekStart)
+		if err != nil {
+			return 0, 0, err
+		}
+		self.lastRuneSize = 1
+		return utf8.RuneError, 1, nil
+	}
+	self.lastRuneSize = size
+	return r, size, nil
+}
+
+// UnreadRune moves the position back by the size of the rune from
+// ReadRune. If Read, Seek, or anything that uses them, came after
+// ReadRune, it returns bufio.ErrInvalidUnreadRune.
+func (self *MultiReadSeeker) UnreadRune() error {
+	if self.lastRuneSize <= 0 {
+		return bufio.ErrInvalidUnreadRune
+	}
+	_, err := self.Seek(-int64(self.lastRuneSize), io.SeekCurrent)
+	return err
+}
@@ -0,0 +1,133 @@
+package multireadseeker
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	. "gopkg.in/check.v1"
+)
+
+var _ io.ByteScanner = (*MultiReadSeeker)(nil)
+var _ io.RuneScanner = (*MultiReadSeeker)(nil)
+
+// A child that can be measured, but whose Reads return nothing
+type stuckChild struct {
+	seekOnlyChild
+	readCalls int
+}
+
+func (self *stuckChild) Read(p []byte) (int, error) {
+	self.readCalls++
+	return 0, nil
+}
+
+func (s *MySuite) TestReadByteNoProgress(c *C) {
+	stuck := &stuckChild{seekOnlyChild: *newSeekOnlyChild("ABC")}
+	mrseeker, err := New(StringChild("X"), stuck)
+	c.Assert(err, IsNil)
+	b, err := mrseeker.ReadByte()
+	c.Assert(err, IsNil)
+	c.Check(b, Equals, byte('X'))
+
+	_, err = mrseeker.ReadByte()
+	c.Check(err, Equals, io.ErrNoProgress)
+	c.Check(stuck.readCalls, Equals, maxConsecutiveEmptyReads)
+	c.Check(mrseeker.Tell(), Equals, int64(1))
+}
+
+func (s *MySuite) TestReadByte(c *C) {
+	mrseeker, err := NewFromStrings("AB", "C")
+	c.Assert(err, IsNil)
+	var got []byte
+	for {
+		b, err := mrseeker.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, IsNil)
+		got = append(got, b)
+	}
+	c.Check(string(got), Equals, "ABC")
+
+	// Back across the start of a child
+	c.Assert(mrseeker.UnreadByte(), IsNil)
+	c.Assert(mrseeker.UnreadByte(), IsNil)
+	c.Check(mrseeker.CurrentChildIndex(), Equals, 0)
+	b, err := mrseeker.ReadByte()
+	c.Assert(err, IsNil)
+	c.Check(b, Equals, byte('B'))
+	b, err = mrseeker.ReadByte()
+	c.Assert(err, IsNil)
+	c.Check(b, Equals, byte('C'))
+
+	_, err = mrseeker.Seek(0, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.UnreadByte(), Equals, bufio.ErrInvalidUnreadByte)
+}
+
+func (s *MySuite) TestReadByteVarint(c *C) {
+	buf := binary.AppendUvarint(nil, 300)
+	mrseeker, err := NewFromBytes(buf[:1], buf[1:])
+	c.Assert(err, IsNil)
+	value, err := binary.ReadUvarint(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(value, Equals, uint64(300))
+}
+
+func (s *MySuite) TestReadRune(c *C) {
+	// "é" is 2 bytes, and "€" is 3; both span a child boundary
+	mrseeker, err := NewFromStrings("aé"[:2], "é"[1:]+"€"[:1], "€"[1:2], "€"[2:]+"b")
+	c.Assert(err, IsNil)
+	var runes []rune
+	var sizes []int
+	for {
+		r, size, err := mrseeker.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, IsNil)
+		runes = append(runes, r)
+		sizes = append(sizes, size)
+	}
+	c.Check(string(runes), Equals, "aé€b")
+	c.Check(sizes, DeepEquals, []int{1, 2, 3, 1})
+
+	_, err = mrseeker.Seek(3, io.SeekStart)
+	c.Assert(err, IsNil)
+	r, _, err := mrseeker.ReadRune()
+	c.Assert(err, IsNil)
+	c.Check(r, Equals, '€')
+	c.Assert(mrseeker.UnreadRune(), IsNil)
+	c.Check(mrseeker.Tell(), Equals, int64(3))
+	c.Check(mrseeker.UnreadRune(), Equals, bufio.ErrInvalidUnreadRune)
+
+	// Invalid UTF-8, and a rune cut short by the end
+	mrseeker, err = NewFromStrings("\xe2x", "\xc3")
+	c.Assert(err, IsNil)
+	r, size, err := mrseeker.ReadRune()
+	c.Assert(err, IsNil)
+	c.Check(r, Equals, utf8.RuneError)
+	c.Check(size, Equals, 1)
+	r, _, err = mrseeker.ReadRune()
+	c.Assert(err, IsNil)
+	c.Check(r, Equals, 'x')
+	r, size, err = mrseeker.ReadRune()
+	c.Assert(err, IsNil)
+	c.Check(r, Equals, utf8.RuneError)
+	c.Check(size, Equals, 1)
+	_, _, err = mrseeker.ReadRune()
+	c.Check(err, Equals, io.EOF)
+}
+
+func (s *MySuite) TestReadRuneFscan(c *C) {
+	mrseeker, err := NewFromStrings("12 ", "3\n")
+	c.Assert(err, IsNil)
+	var a, b int
+	_, err = fmt.Fscan(mrseeker, &a, &b)
+	c.Assert(err, IsNil)
+	c.Check(a, Equals, 12)
+	c.Check(b, Equals, 3)
+}
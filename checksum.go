@@ -0,0 +1,70 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Hashing the bytes of the MultiReadSeeker, or of one child.
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+// Checksum hashes the bytes from the current position to the end with
+// h, and returns h.Sum(nil). It reads with ReadAt, so the position
+// doesn't change.
+func (self *MultiReadSeeker) Checksum(h hash.Hash) ([]byte, error) {
+	return self.checksum(h, self.currentSuperPos, self.size)
+}
+
+// ChecksumChild hashes the bytes of the child at index i with h, and
+// returns h.Sum(nil). As with Checksum, the position doesn't change.
+func (self *MultiReadSeeker) ChecksumChild(i int, h hash.Hash) ([]byte, error) {
+	err := self.checkChildIndex(i)
+	if err != nil {
+		return nil, err
+	}
+	return self.checksum(h, self.superPosStart[i], self.superPosEnd[i])
+}
+
+// Hash the bytes in [start, end)
+func (self *MultiReadSeeker) checksum(h hash.Hash, start, end int64) ([]byte, error) {
+	if self.closed {
+		return nil, ErrClosedSeeker
+	}
+	if start > end {
+		start = end
+	}
+	_, err := io.Copy(h, io.NewSectionReader(self, start, end-start))
+	if err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// MD5 is Checksum with an MD5 hash.
+func (self *MultiReadSeeker) MD5() ([16]byte, error) {
+	var sum [16]byte
+	b, err := self.Checksum(md5.New())
+	copy(sum[:], b)
+	return sum, err
+}
+
+// SHA256 is Checksum with a SHA-256 hash.
+func (self *MultiReadSeeker) SHA256() ([32]byte, error) {
+	var sum [32]byte
+	b, err := self.Checksum(sha256.New())
+	copy(sum[:], b)
+	return sum, err
+}
+
+// CRC32 is Checksum with an IEEE CRC-32.
+func (self *MultiReadSeeker) CRC32() (uint32, error) {
+	h := crc32.NewIEEE()
+	_, err := self.Checksum(h)
+	if err != nil {
+		return 0, err
+	}
+	return h.Sum32(), nil
+}
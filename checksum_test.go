@@ -0,0 +1,75 @@
+package multireadseeker
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"hash/crc32"
+	"io"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestChecksum(c *C) {
+	child0 := []byte("ABCDEF")
+	child1 := []byte("GHIJ")
+	mrseeker, err := New(newSeekOnlyChild(string(child0)),
+		newSeekOnlyChild(string(child1)))
+	c.Assert(err, IsNil)
+
+	sum, err := mrseeker.Checksum(sha256.New())
+	c.Assert(err, IsNil)
+	expected := sha256.Sum256(append(append([]byte{}, child0...), child1...))
+	c.Check(sum, DeepEquals, expected[:])
+
+	// From the current position, which doesn't change
+	_, err = mrseeker.Seek(2, io.SeekStart)
+	c.Assert(err, IsNil)
+	sum, err = mrseeker.Checksum(sha256.New())
+	c.Assert(err, IsNil)
+	expected = sha256.Sum256([]byte("CDEFGHIJ"))
+	c.Check(sum, DeepEquals, expected[:])
+	c.Check(mrseeker.Tell(), Equals, int64(2))
+
+	sum, err = mrseeker.ChecksumChild(1, sha256.New())
+	c.Assert(err, IsNil)
+	expected = sha256.Sum256(child1)
+	c.Check(sum, DeepEquals, expected[:])
+	c.Check(mrseeker.Tell(), Equals, int64(2))
+	buf := make([]byte, 3)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "CDE")
+
+	_, err = mrseeker.ChecksumChild(2, sha256.New())
+	c.Check(errors.Is(err, ErrIndexOutOfRange), Equals, true)
+
+	// A child that fails
+	errBoom := errors.New("boom")
+	bad := newScriptedChild("XYZ")
+	bad.failAt = 1
+	bad.failErr = errBoom
+	mrseeker, err = New(newScriptedChild("ABC"), bad)
+	c.Assert(err, IsNil)
+	_, err = mrseeker.Checksum(sha256.New())
+	c.Check(errors.Is(err, errBoom), Equals, true)
+}
+
+func (s *MySuite) TestChecksumShortcuts(c *C) {
+	mrseeker, err := NewFromStrings("ABC", "DEF")
+	c.Assert(err, IsNil)
+	_, err = mrseeker.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	data := []byte("BCDEF")
+
+	md5Sum, err := mrseeker.MD5()
+	c.Assert(err, IsNil)
+	c.Check(md5Sum, Equals, md5.Sum(data))
+	sha256Sum, err := mrseeker.SHA256()
+	c.Assert(err, IsNil)
+	c.Check(sha256Sum, Equals, sha256.Sum256(data))
+	crc, err := mrseeker.CRC32()
+	c.Assert(err, IsNil)
+	c.Check(crc, Equals, crc32.ChecksumIEEE(data))
+	c.Check(mrseeker.Tell(), Equals, int64(1))
+}
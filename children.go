@@ -0,0 +1,448 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Changing the children of a MultiReadSeeker after it is created.
+// None of these are safe to call while another goroutine is using
+// the MultiReadSeeker; callers must synchronize.
+
+import (
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Append adds children to the end, as when new log files appear. As
+// with Initialize, each child is seeked to its end to find its size,
+// and then back to its start; empty children are skipped, but Close
+// still closes them. If any child can't be seeked, or, with
+// WithEmptyChildPolicy(ErrorOnEmpty), is empty, none of them are added,
+// and they still belong to the caller. The position doesn't change, so
+// a Read that had reached the end carries on with the new children.
+func (self *MultiReadSeeker) Append(children ...ReadCloseSeeker) error {
+	if self.closed {
+		return ErrAlreadyClosed
+	}
+
+	sizes := make([]int64, len(children))
+	for i, child := range children {
+		var err error
+		sizes[i], err = measureChild(child)
+		if err != nil {
+			return err
+		}
+		err = self.checkEmptyChild(i, sizes[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	oldSize := self.size
+	for i, child := range children {
+		if sizes[i] == 0 {
+			self.emptyChildren = append(self.emptyChildren, child)
+			continue
+		}
+		index := len(self.children)
+		self.appendChild(self.wrapChild(child), sizes[i], self.factoryOpener(index))
+		self.usedChild(index)
+	}
+
+	// If the position was beyond the old end, it may be inside one of
+	// the new children; put that child where Read expects it to be.
+	if self.currentSuperPos > oldSize {
+		_, err := self.Seek(self.currentSuperPos, io.SeekStart)
+		return err
+	}
+	return nil
+}
+
+// With WithEmptyChildPolicy(ErrorOnEmpty), the error for adding child i
+// if its size is 0
+func (self *MultiReadSeeker) checkEmptyChild(i int, size int64) error {
+	if size == 0 && self.options.emptyChildPolicy == ErrorOnEmpty {
+		return errors.Wrapf(ErrEmptyChild, "io.Seeker #%d (0-based)", i)
+	}
+	return nil
+}
+
+// Insert adds a child before the child at index i, which must be in
+// [0, NumChildren()]; Insert(NumChildren(), child) is the same as
+// Append(child). As with Append, the child is measured, and skipped if
+// it is empty. A position past the start of child i moves along with
+// the bytes after it, so Read carries on from the same byte; a position
+// at the start of child i now reads the new child.
+func (self *MultiReadSeeker) Insert(i int, child ReadCloseSeeker) error {
+	if self.closed {
+		return ErrAlreadyClosed
+	}
+	if i == len(self.children) {
+		return self.Append(child)
+	}
+	err := self.checkChildIndex(i)
+	if err != nil {
+		return err
+	}
+
+	size, err := measureChild(child)
+	if err != nil {
+		return err
+	}
+	err = self.checkEmptyChild(i, size)
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		self.emptyChildren = append(self.emptyChildren, child)
+		return nil
+	}
+
+	if self.currentSuperPos > self.superPosStart[i] {
+		self.currentSuperPos += size
+		self.currentSeekerNum++
+	}
+	sizes := self.ChildSizes()
+	sizes = append(sizes, 0)
+	copy(sizes[i+1:], sizes[i:])
+	sizes[i] = size
+	self.children = append(self.children, nil)
+	copy(self.children[i+1:], self.children[i:])
+	self.children[i] = self.wrapChild(child)
+	self.openers = append(self.openers, nil)
+	copy(self.openers[i+1:], self.openers[i:])
+	self.openers[i] = nil
+	self.failed = append(self.failed, false)
+	copy(self.failed[i+1:], self.failed[i:])
+	self.failed[i] = false
+	self.names = append(self.names, "")
+	copy(self.names[i+1:], self.names[i:])
+	self.names[i] = ""
+	self.setSizes(sizes)
+	return nil
+}
+
+// Remove closes the child at index i and removes it. The children after
+// it move down by one, and so do their positions. If Read was in the
+// middle of the removed child, it carries on from the start of the
+// next child, or at the end, if there is no next child. The child is
+// removed even if closing it fails; that error is returned.
+func (self *MultiReadSeeker) Remove(i int) error {
+	if self.closed {
+		return ErrAlreadyClosed
+	}
+	err := self.checkChildIndex(i)
+	if err != nil {
+		return err
+	}
+
+	start := self.superPosStart[i]
+	size := self.superPosEnd[i] - start
+	wasCurrent := self.currentSeekerNum == i
+	if self.currentSeekerNum > i {
+		self.currentSeekerNum--
+		self.currentSuperPos -= size
+	}
+
+	err = self.closeChild(i)
+	sizes := self.ChildSizes()
+	sizes = append(sizes[:i], sizes[i+1:]...)
+	self.children = append(self.children[:i], self.children[i+1:]...)
+	self.openers = append(self.openers[:i], self.openers[i+1:]...)
+	self.failed = append(self.failed[:i], self.failed[i+1:]...)
+	self.names = append(self.names[:i], self.names[i+1:]...)
+	self.setSizes(sizes)
+
+	if wasCurrent {
+		_, seekErr := self.Seek(start, io.SeekStart)
+		if err == nil {
+			err = seekErr
+		}
+	}
+	return err
+}
+
+// DetachChild removes the child at index i, as Remove does, but
+// returns it instead of closing it; it now belongs to the caller, who
+// must close it. A child from NewLazy is opened first. The child is
+// left wherever the last Read or Seek left it.
+func (self *MultiReadSeeker) DetachChild(i int) (ReadCloseSeeker, error) {
+	if self.closed {
+		return nil, ErrAlreadyClosed
+	}
+	err := self.checkChildIndex(i)
+	if err != nil {
+		return nil, err
+	}
+	child, err := self.child(i)
+	if err != nil {
+		return nil, err
+	}
+
+	// So that Remove doesn't close it
+	self.children[i] = nil
+	return child, self.Remove(i)
+}
+
+// Replace closes the child at index i and puts newChild in its place,
+// as when a log file has been rewritten. The new child is measured,
+// and the positions of the children after it move by the difference
+// in size. If Read was in the child being replaced, it carries on at
+// the same offset in the new child, or at its end, if the new child is
+// smaller. An empty new child is skipped, so it is like Remove, except
+// that Close also closes the new child. If newChild can't be measured,
+// nothing changes.
+func (self *MultiReadSeeker) Replace(i int, newChild ReadCloseSeeker) error {
+	if self.closed {
+		return ErrAlreadyClosed
+	}
+	err := self.checkChildIndex(i)
+	if err != nil {
+		return err
+	}
+
+	size, err := measureChild(newChild)
+	if err != nil {
+		return err
+	}
+	err = self.checkEmptyChild(i, size)
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		self.emptyChildren = append(self.emptyChildren, newChild)
+		return self.Remove(i)
+	}
+
+	oldSize := self.superPosEnd[i] - self.superPosStart[i]
+	childPos := self.currentSuperPos - self.superPosStart[i]
+	if self.currentSeekerNum > i {
+		self.currentSuperPos += size - oldSize
+	}
+
+	err = self.closeChild(i)
+	sizes := self.ChildSizes()
+	sizes[i] = size
+	self.children[i] = self.wrapChild(newChild)
+	self.openers[i] = nil
+	self.failed[i] = false
+	self.setSizes(sizes)
+
+	if self.currentSeekerNum == i {
+		if childPos > size {
+			childPos = size
+		}
+		self.currentSuperPos = self.superPosStart[i] + childPos
+		self.stats.childSeeked(i)
+		seekErr := seekChild(i, self.children[i], childPos)
+		if seekErr != nil {
+			self.hooks.childError(i, seekErr)
+		}
+		if err == nil {
+			err = seekErr
+		}
+	}
+	return err
+}
+
+// WrapChild replaces the child at index i with fn(child), as to check
+// the CRC of only one child; a child from NewLazy is opened first. The
+// old child now belongs to the new one, so it isn't closed here. As
+// with Replace, the new child is measured, the positions after it move
+// by the difference in size, and Read carries on at the same offset in
+// it; if it is empty, it is removed. If the new child can't be
+// measured, nothing changes.
+func (self *MultiReadSeeker) WrapChild(i int, fn func(ReadCloseSeeker) ReadCloseSeeker) error {
+	if self.closed {
+		return ErrAlreadyClosed
+	}
+	err := self.checkChildIndex(i)
+	if err != nil {
+		return err
+	}
+	child, err := self.child(i)
+	if err != nil {
+		return err
+	}
+
+	newChild := fn(child)
+	size, err := measureChild(newChild)
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		self.emptyChildren = append(self.emptyChildren, newChild)
+		self.children[i] = nil
+		return self.Remove(i)
+	}
+
+	oldSize := self.superPosEnd[i] - self.superPosStart[i]
+	childPos := self.currentSuperPos - self.superPosStart[i]
+	if self.currentSeekerNum > i {
+		self.currentSuperPos += size - oldSize
+	}
+
+	sizes := self.ChildSizes()
+	sizes[i] = size
+	self.children[i] = newChild
+	self.openers[i] = nil
+	self.failed[i] = false
+	self.setSizes(sizes)
+
+	if self.currentSeekerNum == i {
+		if childPos > size {
+			childPos = size
+		}
+		self.currentSuperPos = self.superPosStart[i] + childPos
+		self.stats.childSeeked(i)
+		err = seekChild(i, newChild, childPos)
+		if err != nil {
+			self.hooks.childError(i, err)
+		}
+	}
+	return err
+}
+
+// Swap exchanges the children at indices i and j, and moves the
+// positions of the children between them to match. Read carries on
+// from the same byte of the same child, wherever it is now; at or
+// beyond the end, the position doesn't change.
+func (self *MultiReadSeeker) Swap(i, j int) error {
+	if self.closed {
+		return ErrAlreadyClosed
+	}
+	err := self.checkChildIndex(i)
+	if err != nil {
+		return err
+	}
+	err = self.checkChildIndex(j)
+	if err != nil {
+		return err
+	}
+
+	childPos := self.CurrentChildOffset()
+	sizes := self.ChildSizes()
+	self.swapSlots(sizes, i, j)
+	self.setSizes(sizes)
+
+	if self.currentSuperPos < self.size {
+		switch self.currentSeekerNum {
+		case i:
+			self.currentSeekerNum = j
+		case j:
+			self.currentSeekerNum = i
+		}
+		self.currentSuperPos = self.superPosStart[self.currentSeekerNum] + childPos
+	}
+	return nil
+}
+
+// ReverseChildren reverses the order of the children, as when files
+// were added newest first but should be read oldest first. The
+// position goes back to the start, as with Reset, which is now the
+// start of what was the last child.
+func (self *MultiReadSeeker) ReverseChildren() error {
+	if self.closed {
+		return ErrAlreadyClosed
+	}
+	sizes := self.ChildSizes()
+	for i, j := 0, len(sizes)-1; i < j; i, j = i+1, j-1 {
+		self.swapSlots(sizes, i, j)
+	}
+	self.setSizes(sizes)
+	return self.Reset()
+}
+
+// SortChildren puts the children in the order given by less, as when
+// the files from NewFromDirectory should be read by date. less(i, j)
+// reports whether the child that is at index i now should come before
+// the one at index j; children that neither comes before keep their
+// order. As with ReverseChildren, the position goes back to the start,
+// and an error is returned if a child can't be seeked to its start.
+func (self *MultiReadSeeker) SortChildren(less func(i, j int) bool) error {
+	if self.closed {
+		return ErrAlreadyClosed
+	}
+	order := make([]int, len(self.children))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return less(order[a], order[b])
+	})
+
+	oldSizes := self.ChildSizes()
+	sizes := make([]int64, len(order))
+	children := make([]ReadCloseSeeker, len(order))
+	openers := make([]func() (ReadCloseSeeker, error), len(order))
+	failed := make([]bool, len(order))
+	names := make([]string, len(order))
+	for to, from := range order {
+		sizes[to] = oldSizes[from]
+		children[to] = self.children[from]
+		openers[to] = self.openers[from]
+		failed[to] = self.failed[from]
+		names[to] = self.names[from]
+	}
+	self.children = children
+	self.openers = openers
+	self.failed = failed
+	self.names = names
+	self.setSizes(sizes)
+	return self.Reset()
+}
+
+// SortChildrenBySize sorts the children from the smallest to the
+// largest, with SortChildren.
+func (self *MultiReadSeeker) SortChildrenBySize() error {
+	sizes := self.ChildSizes()
+	return self.SortChildren(func(i, j int) bool {
+		return sizes[i] < sizes[j]
+	})
+}
+
+// SortChildrenBySizeDesc sorts the children from the largest to the
+// smallest, with SortChildren.
+func (self *MultiReadSeeker) SortChildrenBySizeDesc() error {
+	sizes := self.ChildSizes()
+	return self.SortChildren(func(i, j int) bool {
+		return sizes[i] > sizes[j]
+	})
+}
+
+// Swap the children at i and j, and their sizes, without recomputing
+// the positions.
+func (self *MultiReadSeeker) swapSlots(sizes []int64, i, j int) {
+	sizes[i], sizes[j] = sizes[j], sizes[i]
+	self.children[i], self.children[j] = self.children[j], self.children[i]
+	self.openers[i], self.openers[j] = self.openers[j], self.openers[i]
+	self.failed[i], self.failed[j] = self.failed[j], self.failed[i]
+	self.names[i], self.names[j] = self.names[j], self.names[i]
+}
+
+// Recompute the positions of the children from their sizes. The
+// children may have moved, so a read-ahead of the next child is
+// cancelled.
+func (self *MultiReadSeeker) setSizes(sizes []int64) {
+	self.cancelReadAhead()
+	self.superPosStart = make([]int64, len(sizes))
+	self.superPosEnd = make([]int64, len(sizes))
+	self.size = 0
+	for i, size := range sizes {
+		self.superPosStart[i] = self.size
+		self.size += size
+		self.superPosEnd[i] = self.size
+	}
+	self.recountOpenChildren()
+}
+
+// With WithChildFactory, the function that opens the child at index i
+// again; otherwise nil
+func (self *MultiReadSeeker) factoryOpener(i int) func() (ReadCloseSeeker, error) {
+	factory := self.options.childFactory
+	if factory == nil {
+		return nil
+	}
+	return func() (ReadCloseSeeker, error) {
+		return factory(i)
+	}
+}
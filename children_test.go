@@ -0,0 +1,435 @@
+package multireadseeker
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestAppend(c *C) {
+	mrseeker, err := New(newSeekOnlyChild("ABC"))
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABC")
+
+	// Reading carries on with the new children
+	empty := newSeekOnlyChild("")
+	err = mrseeker.Append(newSeekOnlyChild("DE"), empty, newSeekOnlyChild("FGH"))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.NumChildren(), Equals, 3)
+	c.Check(mrseeker.Size(), Equals, int64(8))
+	c.Check(mrseeker.ChildSizes(), DeepEquals, []int64{3, 2, 3})
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "DEFGH")
+
+	// A position beyond the old end is inside a new child
+	_, err = mrseeker.Seek(10, io.SeekStart)
+	c.Assert(err, IsNil)
+	err = mrseeker.Append(newSeekOnlyChild("IJKL"))
+	c.Assert(err, IsNil)
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "KL")
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+	c.Check(empty.closeCalls, Equals, 1)
+
+	err = mrseeker.Append(newSeekOnlyChild("MN"))
+	c.Check(errors.Is(err, ErrAlreadyClosed), Equals, true)
+}
+
+func (s *MySuite) TestAppendToEmpty(c *C) {
+	mrseeker := NewEmpty()
+	err := mrseeker.Append(newSeekOnlyChild("ABC"))
+	c.Assert(err, IsNil)
+	err = mrseeker.Append(newSeekOnlyChild("DEF"))
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEF")
+}
+
+func (s *MySuite) TestAppendSeekFails(c *C) {
+	mrseeker, err := New(newSeekOnlyChild("ABC"))
+	c.Assert(err, IsNil)
+
+	errSeek := errors.New("seek failed")
+	bad := newScriptedChild("XYZ")
+	bad.seekErr = errSeek
+	err = mrseeker.Append(newSeekOnlyChild("DEF"), bad)
+	c.Check(errors.Is(err, errSeek), Equals, true)
+
+	// Nothing was added
+	c.Check(mrseeker.NumChildren(), Equals, 1)
+	c.Check(mrseeker.Size(), Equals, int64(3))
+}
+
+func (s *MySuite) TestInsert(c *C) {
+	mrseeker, err := New(newSeekOnlyChild("ABC"), newSeekOnlyChild("GHI"))
+	c.Assert(err, IsNil)
+
+	// Insert in front of the current child; Read carries on from the
+	// same byte
+	buf := make([]byte, 2)
+	_, err = mrseeker.Seek(4, io.SeekStart)
+	c.Assert(err, IsNil)
+	err = mrseeker.Insert(1, newSeekOnlyChild("DEF"))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.ChildSizes(), DeepEquals, []int64{3, 3, 3})
+	c.Check(mrseeker.Tell(), Equals, int64(7))
+	c.Check(mrseeker.CurrentChildIndex(), Equals, 2)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "HI")
+
+	// Inserting at the current position reads the new child next
+	_, err = mrseeker.Seek(3, io.SeekStart)
+	c.Assert(err, IsNil)
+	err = mrseeker.Insert(1, newSeekOnlyChild("xy"))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Tell(), Equals, int64(3))
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "xyDEFGHI")
+
+	// At the start, and at the end
+	err = mrseeker.Insert(0, newSeekOnlyChild("0"))
+	c.Assert(err, IsNil)
+	err = mrseeker.Insert(mrseeker.NumChildren(), newSeekOnlyChild("J"))
+	c.Assert(err, IsNil)
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "J")
+	_, err = mrseeker.Seek(0, io.SeekStart)
+	c.Assert(err, IsNil)
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "0ABCxyDEFGHIJ")
+
+	err = mrseeker.Insert(-1, newSeekOnlyChild("K"))
+	c.Check(errors.Is(err, ErrIndexOutOfRange), Equals, true)
+	err = mrseeker.Insert(mrseeker.NumChildren()+1, newSeekOnlyChild("K"))
+	c.Check(errors.Is(err, ErrIndexOutOfRange), Equals, true)
+
+	errSeek := errors.New("seek failed")
+	bad := newScriptedChild("XYZ")
+	bad.seekErr = errSeek
+	err = mrseeker.Insert(0, bad)
+	c.Check(errors.Is(err, errSeek), Equals, true)
+	c.Check(mrseeker.NumChildren(), Equals, 6)
+}
+
+func (s *MySuite) TestRemove(c *C) {
+	children := []*seekOnlyChild{
+		newSeekOnlyChild("ABC"),
+		newSeekOnlyChild("DEF"),
+		newSeekOnlyChild("GHI"),
+		newSeekOnlyChild("JKL"),
+	}
+	mrseeker, err := New(children[0], children[1], children[2], children[3])
+	c.Assert(err, IsNil)
+
+	// Removing the current child goes on to the start of the next one
+	buf := make([]byte, 4)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "ABCD")
+	err = mrseeker.Remove(1)
+	c.Assert(err, IsNil)
+	c.Check(children[1].closeCalls, Equals, 1)
+	c.Check(mrseeker.ChildSizes(), DeepEquals, []int64{3, 3, 3})
+	c.Check(mrseeker.Tell(), Equals, int64(3))
+	n, err = mrseeker.Read(buf[:2])
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "GH")
+
+	// Removing an earlier child moves the position back
+	err = mrseeker.Remove(0)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Tell(), Equals, int64(2))
+	n, err = mrseeker.Read(buf[:2])
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "IJ")
+
+	// Removing the last child, while reading it, goes to the end
+	err = mrseeker.Remove(1)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Size(), Equals, int64(3))
+	_, err = mrseeker.Read(buf)
+	c.Check(err, Equals, io.EOF)
+	_, err = mrseeker.Seek(0, io.SeekStart)
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "GHI")
+
+	err = mrseeker.Remove(1)
+	c.Check(errors.Is(err, ErrIndexOutOfRange), Equals, true)
+	err = mrseeker.Remove(0)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.NumChildren(), Equals, 0)
+	_, err = mrseeker.Read(buf)
+	c.Check(err, Equals, io.EOF)
+
+	// Each child was closed once
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+	for _, child := range children {
+		c.Check(child.closeCalls, Equals, 1)
+	}
+}
+
+func (s *MySuite) TestRemoveCloseFails(c *C) {
+	errClose := errors.New("close failed")
+	bad := newScriptedChild("DEF")
+	bad.closeErr = errClose
+	mrseeker, err := New(newSeekOnlyChild("ABC"), bad)
+	c.Assert(err, IsNil)
+
+	// The child is removed anyway
+	err = mrseeker.Remove(1)
+	c.Check(errors.Is(err, errClose), Equals, true)
+	c.Check(mrseeker.NumChildren(), Equals, 1)
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestDetachChild(c *C) {
+	detached := newSeekOnlyChild("DEF")
+	mrseeker, err := New(newSeekOnlyChild("ABC"), detached, newSeekOnlyChild("GHI"))
+	c.Assert(err, IsNil)
+
+	// Read was in the middle of the child; it goes on with the next one
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(mrseeker, buf)
+	c.Assert(err, IsNil)
+	child, err := mrseeker.DetachChild(1)
+	c.Assert(err, IsNil)
+	c.Check(child, Equals, ReadCloseSeeker(detached))
+	c.Check(mrseeker.ChildSizes(), DeepEquals, []int64{3, 3})
+	c.Check(mrseeker.Tell(), Equals, int64(3))
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "GHI")
+
+	_, err = mrseeker.DetachChild(2)
+	c.Check(errors.Is(err, ErrIndexOutOfRange), Equals, true)
+
+	// The detached child isn't closed with the rest
+	c.Assert(mrseeker.Close(), IsNil)
+	c.Check(detached.closeCalls, Equals, 0)
+	data, err = ioutil.ReadAll(child)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "EF")
+}
+
+func (s *MySuite) TestReplace(c *C) {
+	old := newSeekOnlyChild("DEF")
+	mrseeker, err := New(newSeekOnlyChild("ABC"), old, newSeekOnlyChild("GHI"))
+	c.Assert(err, IsNil)
+
+	// The file grew while it was being read
+	buf := make([]byte, 5)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "ABCDE")
+	err = mrseeker.Replace(1, newSeekOnlyChild("DEFXY"))
+	c.Assert(err, IsNil)
+	c.Check(old.closeCalls, Equals, 1)
+	c.Check(mrseeker.ChildSizes(), DeepEquals, []int64{3, 5, 3})
+	c.Check(mrseeker.Tell(), Equals, int64(5))
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "FXYGHI")
+
+	// Replacing an earlier child moves the position
+	_, err = mrseeker.Seek(9, io.SeekStart)
+	c.Assert(err, IsNil)
+	err = mrseeker.Replace(0, newSeekOnlyChild("A"))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Tell(), Equals, int64(7))
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "HI")
+
+	// A smaller child leaves Read at its end
+	_, err = mrseeker.Seek(5, io.SeekStart)
+	c.Assert(err, IsNil)
+	err = mrseeker.Replace(1, newSeekOnlyChild("de"))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Tell(), Equals, int64(3))
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "GHI")
+
+	// An empty child removes the old one
+	empty := newSeekOnlyChild("")
+	err = mrseeker.Replace(0, empty)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.ChildSizes(), DeepEquals, []int64{2, 3})
+
+	errSeek := errors.New("seek failed")
+	bad := newScriptedChild("XYZ")
+	bad.seekErr = errSeek
+	err = mrseeker.Replace(0, bad)
+	c.Check(errors.Is(err, errSeek), Equals, true)
+	c.Check(mrseeker.ChildSizes(), DeepEquals, []int64{2, 3})
+	err = mrseeker.Replace(2, newSeekOnlyChild("Z"))
+	c.Check(errors.Is(err, ErrIndexOutOfRange), Equals, true)
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+	c.Check(empty.closeCalls, Equals, 1)
+}
+
+func (s *MySuite) TestWrapChild(c *C) {
+	old := newSeekOnlyChild("DEFG")
+	mrseeker, err := New(newSeekOnlyChild("ABC"), old, newSeekOnlyChild("HIJ"))
+	c.Assert(err, IsNil)
+
+	// Only read the first 2 bytes of child 1, which is being read
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(mrseeker, buf)
+	c.Assert(err, IsNil)
+	err = mrseeker.WrapChild(1, func(child ReadCloseSeeker) ReadCloseSeeker {
+		return LimitedReadCloseSeeker(child, 3)
+	})
+	c.Assert(err, IsNil)
+	c.Check(old.closeCalls, Equals, 0)
+	c.Check(mrseeker.ChildSizes(), DeepEquals, []int64{3, 3, 3})
+	c.Check(mrseeker.Size(), Equals, int64(9))
+	c.Check(mrseeker.Tell(), Equals, int64(5))
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "FHIJ")
+
+	// The wrapper can't be measured; nothing changes
+	scripted := newScriptedChild("XYZ")
+	scripted.seekErr = errors.New("no seeking")
+	err = mrseeker.WrapChild(0, func(child ReadCloseSeeker) ReadCloseSeeker {
+		return scripted
+	})
+	c.Check(errors.Cause(err), Equals, scripted.seekErr)
+	c.Check(mrseeker.ChildSizes(), DeepEquals, []int64{3, 3, 3})
+
+	err = mrseeker.WrapChild(3, func(child ReadCloseSeeker) ReadCloseSeeker {
+		return child
+	})
+	c.Check(errors.Is(err, ErrIndexOutOfRange), Equals, true)
+
+	// Closing closes the old child through its wrapper
+	c.Assert(mrseeker.Close(), IsNil)
+	c.Check(old.closeCalls, Equals, 1)
+}
+
+func (s *MySuite) TestSortChildren(c *C) {
+	mrseeker, err := NewFromNamedChildren(
+		NamedChild{"c", StringChild("CCC")},
+		NamedChild{"a", StringChild("A")},
+		NamedChild{"b", StringChild("BB")},
+		NamedChild{"d", StringChild("DD")})
+	c.Assert(err, IsNil)
+	_, err = mrseeker.Seek(5, io.SeekStart)
+	c.Assert(err, IsNil)
+
+	names := mrseeker.ChildNames()
+	err = mrseeker.SortChildren(func(i, j int) bool {
+		return names[i] < names[j]
+	})
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.ChildNames(), DeepEquals, []string{"a", "b", "c", "d"})
+	c.Check(mrseeker.Tell(), Equals, int64(0))
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABBCCCDD")
+
+	// Children of the same size keep their order
+	err = mrseeker.SortChildrenBySizeDesc()
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.ChildNames(), DeepEquals, []string{"c", "b", "d", "a"})
+	err = mrseeker.SortChildrenBySize()
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.ChildNames(), DeepEquals, []string{"a", "b", "d", "c"})
+	start, err := mrseeker.ChildStartPos(3)
+	c.Assert(err, IsNil)
+	c.Check(start, Equals, int64(5))
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABBDDCCC")
+}
+
+func (s *MySuite) TestSwap(c *C) {
+	mrseeker, err := New(newSeekOnlyChild("AB"), newSeekOnlyChild("CDE"),
+		newSeekOnlyChild("FGHI"))
+	c.Assert(err, IsNil)
+
+	// Reading the first child, which moves to the end
+	buf := make([]byte, 1)
+	_, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	err = mrseeker.Swap(0, 2)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.ChildSizes(), DeepEquals, []int64{4, 3, 2})
+	c.Check(mrseeker.CurrentChildIndex(), Equals, 2)
+	c.Check(mrseeker.Tell(), Equals, int64(8))
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "B")
+
+	// Reading a child between the two
+	_, err = mrseeker.Seek(5, io.SeekStart)
+	c.Assert(err, IsNil)
+	err = mrseeker.Swap(2, 0)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.CurrentChildIndex(), Equals, 1)
+	c.Check(mrseeker.Tell(), Equals, int64(3))
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "DEFGHI")
+
+	// At the end, the position doesn't change
+	err = mrseeker.Swap(1, 2)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Tell(), Equals, int64(9))
+	_, err = mrseeker.Seek(0, io.SeekStart)
+	c.Assert(err, IsNil)
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABFGHICDE")
+
+	err = mrseeker.Swap(0, 3)
+	c.Check(errors.Is(err, ErrIndexOutOfRange), Equals, true)
+	err = mrseeker.Swap(-1, 0)
+	c.Check(errors.Is(err, ErrIndexOutOfRange), Equals, true)
+}
+
+func (s *MySuite) TestReverseChildren(c *C) {
+	mrseeker, err := New(newSeekOnlyChild("3"), newSeekOnlyChild("22"),
+		newSeekOnlyChild("111"))
+	c.Assert(err, IsNil)
+	_, err = mrseeker.Seek(4, io.SeekStart)
+	c.Assert(err, IsNil)
+
+	err = mrseeker.ReverseChildren()
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.ChildSizes(), DeepEquals, []int64{3, 2, 1})
+	c.Check(mrseeker.Tell(), Equals, int64(0))
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "111223")
+
+	_, err = mrseeker.Seek(0, WHENCE_START)
+	c.Assert(err, IsNil)
+	buf := make([]byte, 1)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "1")
+
+	err = NewEmpty().ReverseChildren()
+	c.Check(err, IsNil)
+}
@@ -0,0 +1,490 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// ConcatFile treats a sequence of files, given by name, as a single
+// file. Unlike MultiReadSeeker, which is given children that are
+// already open, ConcatFile keeps only one of the files open at a time,
+// or, with WithMaxOpenFiles, a few of them, so it can be used with more
+// files than the OS lets a process have open at once.
+
+import (
+	"io"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+type ConcatFile struct {
+	// Is the ConcatFile currently open?
+	// We mark it as closed after an unrecoverable error
+	currentlyOpen bool
+
+	// All the filenames that make up the complete data set.
+	filenames []string
+
+	// Number of files (length of 'filenames')
+	numFiles int
+
+	// The currently open file number
+	currentFileNum int
+
+	// The currently opened filehandle, and the position within it.
+	// In lazy mode, this is nil until the file is read.
+	fh *os.File
+
+	// Open each file only when reading reaches it
+	lazy bool
+
+	// Files besides the current one that are kept open, for
+	// WithMaxOpenFiles; nil without it
+	openFiles *fileCache
+
+	// Our position (across the sequence of all files)
+	superPos int64
+
+	// Our complete size (across the sequence of all files)
+	superSize int64
+
+	// Position/size info for all the files. As with MultiReadSeeker,
+	// superPosEnd is one past the last position in the file.
+	superPosStart []int64
+	superPosEnd   []int64
+}
+
+// Open returns a new ConcatFile, similar to os.Open(), except
+// that the input is a slice of file names, as opposed to just one.
+func Open(names []string) (*ConcatFile, error) {
+	return openConcatFile(nil, names, false)
+}
+
+// OpenWithOptions is Open, with options; WithMaxOpenFiles is the one
+// that applies.
+func OpenWithOptions(opts []Option, names []string) (*ConcatFile, error) {
+	return openConcatFile(opts, names, false)
+}
+
+// OpenLazy returns a new ConcatFile that doesn't open any file until
+// reading reaches it, and closes each file as soon as reading moves on
+// to another one. The files are only stat'ed, not opened, to find
+// their sizes, so permission problems aren't found until a file is read.
+func OpenLazy(names []string) (*ConcatFile, error) {
+	return openConcatFile(nil, names, true)
+}
+
+// OpenLazyWithOptions is OpenLazy, with options; WithMaxOpenFiles is
+// the one that applies.
+func OpenLazyWithOptions(opts []Option, names []string) (*ConcatFile, error) {
+	return openConcatFile(opts, names, true)
+}
+
+func openConcatFile(opts []Option, names []string, lazy bool) (*ConcatFile, error) {
+	// Ensure we have at least one file
+	if len(names) == 0 {
+		return nil, errors.Wrap(ErrNoChildren, "At least one file name is required")
+	}
+
+	// Create the new object and analyze the files to
+	// fill in the object data
+	self := &ConcatFile{
+		filenames:     names,
+		numFiles:      len(names),
+		lazy:          lazy,
+		superPosStart: make([]int64, len(names)),
+		superPosEnd:   make([]int64, len(names)),
+	}
+	if maxOpenFiles := newOptions(opts).maxOpenFiles; maxOpenFiles > 1 {
+		self.openFiles = newFileCache(maxOpenFiles - 1)
+	}
+
+	// Go through each file and find its size
+	for i, name := range names {
+		fileinfo, err := self.statFile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		// This file starts where the previous file ends
+		if i > 0 {
+			self.superPosStart[i] = self.superPosEnd[i-1]
+		}
+		self.superPosEnd[i] = self.superPosStart[i] + fileinfo.Size()
+	}
+	// Our super-size
+	self.superSize = self.superPosEnd[self.numFiles-1]
+
+	// Open the first file
+	if !lazy {
+		var err error
+		self.fh, err = os.Open(names[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+	self.currentlyOpen = true
+
+	return self, nil
+}
+
+func (self *ConcatFile) statFile(name string) (os.FileInfo, error) {
+	if self.lazy {
+		return os.Stat(name)
+	}
+
+	// Any permission problems?
+	fh, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close() // ignore any error
+	return fh.Stat()
+}
+
+// Close() a ConcatFile
+func (self *ConcatFile) Close() error {
+	if self.currentlyOpen {
+		self.currentlyOpen = false
+		err := self.closeCurrentFile()
+		cacheErr := self.openFiles.closeAll()
+		if err == nil {
+			err = cacheErr
+		}
+		return err
+	} else {
+		return nil
+	}
+}
+
+// Mark the ConcatFile as closed after an unrecoverable error.
+func (self *ConcatFile) fail() {
+	if self.currentlyOpen {
+		self.currentlyOpen = false
+		self.closeCurrentFile()   // ignore any error
+		self.openFiles.closeAll() // ignore any error
+	}
+}
+
+func (self *ConcatFile) closeCurrentFile() error {
+	if self.fh == nil {
+		return nil
+	}
+	err := self.fh.Close()
+	self.fh = nil
+	return err
+}
+
+// Move on from the current file, keeping it open if WithMaxOpenFiles
+// allows, or else closing it.
+func (self *ConcatFile) releaseCurrentFile() error {
+	if self.fh == nil {
+		return nil
+	}
+	err := self.openFiles.put(self.currentFileNum, self.fh)
+	self.fh = nil
+	return err
+}
+
+// In lazy mode, open the current file and seek to the current position,
+// if that hasn't been done yet. A file that ReadAt kept open is used
+// without opening it again.
+func (self *ConcatFile) openCurrentFile() error {
+	if self.fh != nil {
+		return nil
+	}
+	fh := self.openFiles.take(self.currentFileNum)
+	if fh == nil {
+		var err error
+		fh, err = os.Open(self.filenames[self.currentFileNum])
+		if err != nil {
+			self.fail()
+			return err
+		}
+	}
+	offset := self.superPos - self.superPosStart[self.currentFileNum]
+	_, err := fh.Seek(offset, io.SeekStart)
+	if err != nil {
+		fh.Close() // ignore any error
+		self.fail()
+		return err
+	}
+	self.fh = fh
+	return nil
+}
+
+// Read up to len(b) bytes. When the current file is exhausted,
+// reading continues with the next one.
+// See:
+// http://golang.org/pkg/io/#Reader
+func (self *ConcatFile) Read(b []byte) (int, error) {
+	if !self.currentlyOpen {
+		return 0, os.ErrClosed
+	}
+
+	numRead := 0
+	for len(b) > 0 {
+		if self.superPos >= self.superSize {
+			if numRead == 0 {
+				return 0, io.EOF
+			}
+			break
+		}
+
+		// Go to the next file if we are at the end of this one,
+		// passing over any empty files
+		for self.superPos == self.superPosEnd[self.currentFileNum] {
+			err := self.goToFile(self.currentFileNum + 1)
+			if err != nil {
+				return numRead, err
+			}
+		}
+		err := self.openCurrentFile()
+		if err != nil {
+			return numRead, err
+		}
+
+		// Don't read beyond the size we found for this file
+		buf := b
+		fileRemaining := self.superPosEnd[self.currentFileNum] - self.superPos
+		if int64(len(buf)) > fileRemaining {
+			buf = buf[:fileRemaining]
+		}
+
+		n, err := self.fh.Read(buf)
+		numRead += n
+		b = b[n:]
+		self.superPos += int64(n)
+
+		if err == io.EOF {
+			// The file is shorter than when we opened it. Return what
+			// we have, and report the problem on the next Read.
+			if self.superPos < self.superPosEnd[self.currentFileNum] {
+				if numRead > 0 {
+					break
+				}
+				return 0, errors.Wrapf(io.ErrUnexpectedEOF, "Reading %s",
+					self.filenames[self.currentFileNum])
+			}
+		} else if err != nil {
+			return numRead, err
+		} else if n == 0 {
+			break
+		}
+	}
+	return numRead, nil
+}
+
+// Close the current file and open another one, positioned at its start.
+// In lazy mode, the other file isn't opened until it is read. With
+// WithMaxOpenFiles, the current file is kept open instead, and the
+// other one is used without opening it again if it was kept open.
+func (self *ConcatFile) goToFile(fileNum int) error {
+	// Take the other file from the files kept open first, so that
+	// keeping the current one open doesn't close it
+	next := self.openFiles.take(fileNum)
+
+	// Close the current file
+	err := self.releaseCurrentFile()
+	if err != nil {
+		if next != nil {
+			next.Close() // ignore any error
+		}
+		self.fail()
+		return err
+	}
+	self.currentFileNum = fileNum
+	if next != nil {
+		_, err = next.Seek(0, io.SeekStart)
+		if err != nil {
+			next.Close() // ignore any error
+			self.fail()
+			return err
+		}
+		self.fh = next
+		return nil
+	}
+	if self.lazy {
+		return nil
+	}
+
+	// Open the next file
+	self.fh, err = os.Open(self.filenames[fileNum])
+	if err != nil {
+		self.fail()
+		return err
+	}
+	return nil
+}
+
+// Seek sets the offset for the next Read on file to offset,
+// interpreted according to whence:
+//
+//	io.SeekStart means relative to the origin of the file,
+//	io.SeekCurrent means relative to the current offset, and
+//	io.SeekEnd means relative to the end.
+//
+// It returns the new offset and an error, if any.
+func (self *ConcatFile) Seek(offset int64, whence int) (int64, error) {
+	if !self.currentlyOpen {
+		return self.superPos, os.ErrClosed
+	}
+
+	// Calculate the new super position
+	var newSuperPos int64
+	switch whence {
+	case io.SeekStart:
+		newSuperPos = offset
+	case io.SeekCurrent:
+		// Special case: no change
+		if offset == 0 {
+			return self.superPos, nil
+		}
+		newSuperPos = self.superPos + offset
+	case io.SeekEnd:
+		// offset must be 0 or negative
+		if offset > 0 {
+			return self.superPos, errors.Wrapf(ErrSeekPastEnd,
+				"Seek(%d, io.SeekEnd) with size %d; offset must be <= 0",
+				offset, self.superSize)
+		}
+		newSuperPos = self.superSize + offset
+	default:
+		return self.superPos, errors.Errorf(
+			"Seek(offset, whence); whence must be 0, 1, or 2, not %d", whence)
+	}
+
+	if newSuperPos < 0 {
+		return self.superPos, errors.Wrapf(ErrNegativeSeek, "Seek to %d", newSuperPos)
+	}
+
+	// If it's at or beyond the end, go to the last file
+	seekIndex := self.findSeekIndex(newSuperPos)
+	if seekIndex == seekImpossible {
+		seekIndex = self.numFiles - 1
+	}
+
+	// Do we need to change files?
+	if seekIndex != self.currentFileNum {
+		err := self.goToFile(seekIndex)
+		if err != nil {
+			return self.superPos, err
+		}
+	}
+
+	// A file that isn't open yet will be seeked when it is opened
+	if self.fh == nil {
+		self.superPos = newSuperPos
+		return self.superPos, nil
+	}
+
+	// Seek to the absolute position in the correct file
+	offset = newSuperPos - self.superPosStart[seekIndex]
+	thisFileOffset, err := self.fh.Seek(offset, io.SeekStart)
+	if err != nil {
+		self.fail()
+		self.superPos = self.superPosStart[seekIndex] + thisFileOffset
+		return self.superPos, err
+	}
+	self.superPos = newSuperPos
+	return self.superPos, nil
+}
+
+// Given a super position, return the index of the file where that
+// index will be located. If we have no such file, return seekImpossible (-1)
+func (self *ConcatFile) findSeekIndex(newSuperPos int64) int {
+
+	// The super position must not be negative
+	if newSuperPos < 0 {
+		return seekImpossible
+	}
+
+	// The first file that ends after newSuperPos; the ends are in
+	// order, and empty files, which end where they start, are passed over
+	i := sort.Search(self.numFiles, func(i int) bool {
+		return self.superPosEnd[i] > newSuperPos
+	})
+
+	// Beyond the end?
+	if i == self.numFiles {
+		return seekImpossible
+	}
+	return i
+}
+
+// ReadAt reads len(p) bytes starting at offset off, following the
+// io.ReaderAt contract. The position used by Read and Seek is unchanged;
+// the file that is currently open is read without moving its offset, and
+// any other file is opened just for this call.
+func (self *ConcatFile) ReadAt(p []byte, off int64) (int, error) {
+	if !self.currentlyOpen {
+		return 0, os.ErrClosed
+	}
+	if off < 0 {
+		return 0, errors.Wrapf(ErrNegativeSeek, "ReadAt offset %d", off)
+	}
+
+	numRead := 0
+	for len(p) > 0 {
+		if off >= self.superSize {
+			return numRead, io.EOF
+		}
+		fileNum := self.findSeekIndex(off)
+
+		// Don't read beyond the size we found for this file
+		buf := p
+		fileRemaining := self.superPosEnd[fileNum] - off
+		if int64(len(buf)) > fileRemaining {
+			buf = buf[:fileRemaining]
+		}
+
+		n, err := self.readFileAt(fileNum, buf, off-self.superPosStart[fileNum])
+		numRead += n
+		p = p[n:]
+		off += int64(n)
+		if err != nil {
+			return numRead, err
+		}
+	}
+	return numRead, nil
+}
+
+// Read all of b from one file, opening it just for this read if
+// it isn't the current file, or one of the files kept open
+func (self *ConcatFile) readFileAt(fileNum int, b []byte, offset int64) (int, error) {
+	fh := self.fh
+	if fh == nil || fileNum != self.currentFileNum {
+		fh = self.openFiles.take(fileNum)
+		if fh == nil && self.fh != nil {
+			// Another file is open besides the current one, for now
+			self.openFiles.makeRoom()
+		}
+		if fh == nil {
+			var err error
+			fh, err = os.Open(self.filenames[fileNum])
+			if err != nil {
+				return 0, err
+			}
+		}
+		defer self.openFiles.put(fileNum, fh) // ignore any error
+	}
+
+	n, err := fh.ReadAt(b, offset)
+	if n == len(b) {
+		return n, nil
+	}
+	if err == io.EOF {
+		// The file is shorter than when we opened it
+		err = io.ErrUnexpectedEOF
+	}
+	return n, errors.Wrapf(err, "Reading %s", self.filenames[fileNum])
+}
+
+// Technically this should check for self.currentlyOpen,
+// be we already know the position and we don't have to
+// access the underlying filehandle, so just return the value.
+func (self *ConcatFile) Tell() int64 {
+	return self.superPos
+}
+
+func (self *ConcatFile) Size() int64 {
+	return self.superSize
+}
@@ -0,0 +1,331 @@
+package multireadseeker
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+// Write each of the contents to its own file in the suite's temporary
+// directory, and return the file names.
+func (s *MySuite) writeDataFiles(c *C, prefix string, contents ...string) []string {
+	names := make([]string, len(contents))
+	for i, content := range contents {
+		names[i] = filepath.Join(s.tmpDir, fmt.Sprintf("%s%d", prefix, i))
+		err := ioutil.WriteFile(names[i], []byte(content), 0664)
+		c.Assert(err, IsNil)
+	}
+	return names
+}
+
+func (s *MySuite) TestConcatFileRead(c *C) {
+	names := s.writeDataFiles(c, "cfread", "ABC", "", "DEFG", "HI")
+	cfile, err := Open(names)
+	c.Assert(err, IsNil)
+	c.Check(cfile.Size(), Equals, int64(9))
+
+	buf := make([]byte, 2)
+	n, err := cfile.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "AB")
+
+	// Across the empty file, into the third file
+	buf = make([]byte, 5)
+	n, err = cfile.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "CDEFG")
+	c.Check(cfile.Tell(), Equals, int64(7))
+
+	data, err := ioutil.ReadAll(cfile)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "HI")
+
+	n, err = cfile.Read(buf)
+	c.Check(n, Equals, 0)
+	c.Check(err, Equals, io.EOF)
+
+	err = cfile.Close()
+	c.Assert(err, IsNil)
+	_, err = cfile.Read(buf)
+	c.Check(err, Equals, os.ErrClosed)
+}
+
+func (s *MySuite) TestConcatFileSeek(c *C) {
+	names := s.writeDataFiles(c, "cfseek", "ABC", "DEFG", "HI")
+	cfile, err := Open(names)
+	c.Assert(err, IsNil)
+
+	// Every byte, through Seek
+	for i := 0; i < 9; i++ {
+		pos, err := cfile.Seek(int64(i), io.SeekStart)
+		c.Assert(err, IsNil)
+		c.Check(pos, Equals, int64(i))
+		buf := make([]byte, 1)
+		n, err := cfile.Read(buf)
+		c.Assert(err, IsNil)
+		c.Assert(n, Equals, 1)
+		c.Check(buf[0], Equals, "ABCDEFGHI"[i])
+	}
+
+	pos, err := cfile.Seek(-6, io.SeekCurrent)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(3))
+	buf := make([]byte, 4)
+	n, err := cfile.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "DEFG")
+
+	pos, err = cfile.Seek(-7, io.SeekEnd)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(2))
+	n, err = cfile.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "CDEF")
+
+	_, err = cfile.Seek(1, io.SeekEnd)
+	c.Check(errors.Is(err, ErrSeekPastEnd), Equals, true)
+	_, err = cfile.Seek(-1, io.SeekStart)
+	c.Check(err, NotNil)
+
+	// Beyond the end
+	pos, err = cfile.Seek(20, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(20))
+	_, err = cfile.Read(buf)
+	c.Check(err, Equals, io.EOF)
+
+	err = cfile.Close()
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestConcatFileReadAt(c *C) {
+	names := s.writeDataFiles(c, "cfreadat", "ABC", "DEFG", "HI")
+	cfile, err := Open(names)
+	c.Assert(err, IsNil)
+
+	buf := make([]byte, 1)
+	_, err = cfile.Read(buf)
+	c.Assert(err, IsNil)
+
+	// Spanning all three files
+	buf = make([]byte, 7)
+	n, err := cfile.ReadAt(buf, 1)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "BCDEFGH")
+
+	n, err = cfile.ReadAt(buf, 5)
+	c.Check(err, Equals, io.EOF)
+	c.Check(string(buf[:n]), Equals, "FGHI")
+
+	// The Read position didn't change
+	c.Check(cfile.Tell(), Equals, int64(1))
+	buf = make([]byte, 3)
+	n, err = cfile.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "BCD")
+
+	err = cfile.Close()
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestConcatFileReadAtMatchesRead(c *C) {
+	names := s.writeDataFiles(c, "cfreadatread", "ABC", "", "DEFG", "HI")
+	for _, open := range []func([]string) (*ConcatFile, error){Open, OpenLazy} {
+		cfile, err := open(names)
+		c.Assert(err, IsNil)
+
+		readAtBuf := make([]byte, 9)
+		n, err := cfile.ReadAt(readAtBuf, 0)
+		c.Assert(err, IsNil)
+		c.Check(n, Equals, 9)
+
+		readBuf := make([]byte, 9)
+		n, err = io.ReadFull(cfile, readBuf)
+		c.Assert(err, IsNil)
+		c.Check(n, Equals, 9)
+		c.Check(readAtBuf, DeepEquals, readBuf)
+
+		// Reading the current file at an offset doesn't move it
+		_, err = cfile.Seek(4, io.SeekStart)
+		c.Assert(err, IsNil)
+		n, err = cfile.ReadAt(readAtBuf[:2], 3)
+		c.Assert(err, IsNil)
+		c.Check(string(readAtBuf[:n]), Equals, "DE")
+		n, err = cfile.Read(readBuf[:2])
+		c.Assert(err, IsNil)
+		c.Check(string(readBuf[:n]), Equals, "EF")
+
+		err = cfile.Close()
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *MySuite) TestConcatFileOpenErrors(c *C) {
+	_, err := Open(nil)
+	c.Check(errors.Is(err, ErrNoChildren), Equals, true)
+
+	_, err = Open([]string{filepath.Join(s.tmpDir, "does-not-exist")})
+	c.Check(os.IsNotExist(errors.Cause(err)), Equals, true)
+}
+
+func (s *MySuite) TestConcatFileLazy(c *C) {
+	names := s.writeDataFiles(c, "cflazy", "ABC", "", "DEFG", "HI")
+	cfile, err := OpenLazy(names)
+	c.Assert(err, IsNil)
+	c.Check(cfile.Size(), Equals, int64(9))
+	c.Check(cfile.fh, IsNil)
+
+	buf := make([]byte, 2)
+	n, err := cfile.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "AB")
+	c.Assert(cfile.fh, NotNil)
+	c.Check(cfile.fh.Name(), Equals, names[0])
+
+	// Seeking to another file closes the current one, and doesn't
+	// open the new one yet
+	_, err = cfile.Seek(5, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Check(cfile.fh, IsNil)
+	c.Check(cfile.currentFileNum, Equals, 2)
+
+	n, err = cfile.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "FG")
+	c.Check(cfile.fh.Name(), Equals, names[2])
+
+	_, err = cfile.Seek(0, io.SeekStart)
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(cfile)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFGHI")
+
+	err = cfile.Close()
+	c.Assert(err, IsNil)
+	c.Check(cfile.fh, IsNil)
+}
+
+func (s *MySuite) TestConcatFileLazyMissingFile(c *C) {
+	names := s.writeDataFiles(c, "cflazymissing", "ABC", "DEF")
+	cfile, err := OpenLazy(names)
+	c.Assert(err, IsNil)
+
+	// The file disappears after the ConcatFile was created
+	err = os.Remove(names[1])
+	c.Assert(err, IsNil)
+
+	buf := make([]byte, 6)
+	n, err := cfile.Read(buf)
+	c.Check(string(buf[:n]), Equals, "ABC")
+	c.Check(os.IsNotExist(err), Equals, true)
+}
+
+func (s *MySuite) TestConcatFileMaxOpenFiles(c *C) {
+	names := s.writeDataFiles(c, "cfmaxopen", "ABC", "DEF", "GHI", "JKL")
+	cfile, err := OpenWithOptions([]Option{WithMaxOpenFiles(3)}, names)
+	c.Assert(err, IsNil)
+
+	data, err := ioutil.ReadAll(cfile)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFGHIJKL")
+
+	// The current file, and the two that were read most recently
+	c.Check(cfile.currentFileNum, Equals, 3)
+	c.Check(cfile.openFiles.len(), Equals, 2)
+	_, ok := cfile.openFiles.files[0]
+	c.Check(ok, Equals, false)
+	kept := cfile.openFiles.files[1].Value.(*cachedFile).fh
+
+	// Going back uses the file that was kept open, at the right offset
+	_, err = cfile.Seek(4, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Check(cfile.fh, Equals, kept)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(cfile, buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf), Equals, "EFGH")
+
+	// ReadAt of a file that isn't open keeps it open, closing the
+	// least recently used one
+	_, err = cfile.ReadAt(buf[:2], 0)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:2]), Equals, "AB")
+	c.Check(cfile.openFiles.len(), Equals, 2)
+	_, ok = cfile.openFiles.files[0]
+	c.Check(ok, Equals, true)
+
+	err = cfile.Close()
+	c.Assert(err, IsNil)
+	c.Check(cfile.openFiles.len(), Equals, 0)
+	_, err = kept.Stat()
+	c.Check(errors.Is(err, os.ErrClosed), Equals, true)
+}
+
+func (s *MySuite) TestConcatFileLazyMaxOpenFiles(c *C) {
+	names := s.writeDataFiles(c, "cflazymaxopen", "ABC", "DEF")
+	cfile, err := OpenLazyWithOptions([]Option{WithMaxOpenFiles(2)}, names)
+	c.Assert(err, IsNil)
+
+	buf := make([]byte, 2)
+	for i, pos := range []int64{0, 3, 1, 4} {
+		_, err = cfile.Seek(pos, io.SeekStart)
+		c.Assert(err, IsNil)
+		_, err = io.ReadFull(cfile, buf)
+		c.Assert(err, IsNil)
+		c.Check(string(buf), Equals, "ABCDEF"[pos:pos+2])
+		if i > 0 {
+			// The other file is kept open
+			c.Check(cfile.openFiles.len(), Equals, 1)
+		}
+	}
+	c.Assert(cfile.Close(), IsNil)
+}
+
+func (s *MySuite) TestConcatFileLazyMaxOpenFilesReadAt(c *C) {
+	names := s.writeDataFiles(c, "cflazymaxopenreadat", "ABC", "DEF", "GHI")
+	cfile, err := OpenLazyWithOptions([]Option{WithMaxOpenFiles(3)}, names)
+	c.Assert(err, IsNil)
+
+	// ReadAt of the current file, before Read has opened it, keeps it
+	// open; Read then uses that file
+	buf := make([]byte, 2)
+	_, err = cfile.ReadAt(buf, 1)
+	c.Assert(err, IsNil)
+	c.Check(string(buf), Equals, "BC")
+	c.Check(cfile.openFiles.len(), Equals, 1)
+	kept := cfile.openFiles.files[0].Value.(*cachedFile).fh
+	_, err = io.ReadFull(cfile, buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf), Equals, "AB")
+	c.Check(cfile.fh, Equals, kept)
+	c.Check(cfile.openFiles.len(), Equals, 0)
+
+	// Moving on keeps one entry for the file
+	_, err = io.ReadFull(cfile, buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf), Equals, "CD")
+	c.Check(cfile.openFiles.len(), Equals, 1)
+	c.Check(cfile.openFiles.files, HasLen, 1)
+
+	// A second handle for a file that is kept open replaces the first
+	other, err := os.Open(names[0])
+	c.Assert(err, IsNil)
+	c.Assert(cfile.openFiles.put(0, other), IsNil)
+	c.Check(cfile.openFiles.len(), Equals, 1)
+	c.Check(cfile.openFiles.files, HasLen, 1)
+	_, err = kept.Stat()
+	c.Check(errors.Is(err, os.ErrClosed), Equals, true)
+
+	data, err := ioutil.ReadAll(cfile)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "EFGHI")
+	c.Check(cfile.openFiles.len(), Equals, 2)
+	c.Check(cfile.openFiles.files, HasLen, 2)
+	c.Assert(cfile.Close(), IsNil)
+	c.Check(cfile.openFiles.len(), Equals, 0)
+}
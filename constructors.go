@@ -0,0 +1,278 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Convenience constructors for MultiReadSeeker, for common kinds of
+// children.
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Opens a file for NewFromFiles. Tests replace it to make an open fail.
+var openFile = func(name string) (ReadCloseSeeker, error) {
+	fh, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return fh, nil
+}
+
+// NewFromFiles opens each of the files and returns a MultiReadSeeker
+// with them as its children. All the files are checked for existence
+// before any are opened. If any file can't be opened, the ones that
+// were already opened are closed.
+func NewFromFiles(paths ...string) (*MultiReadSeeker, error) {
+	for _, path := range paths {
+		_, err := os.Stat(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Checking %s", path)
+		}
+	}
+
+	children := make([]ReadCloseSeeker, 0, len(paths))
+	for _, path := range paths {
+		child, err := openFile(path)
+		if err != nil {
+			closeAll(children)
+			return nil, errors.Wrapf(err, "Opening %s", path)
+		}
+		children = append(children, child)
+	}
+
+	mrseeker, err := New(children...)
+	if err != nil {
+		closeAll(children)
+		return nil, err
+	}
+	return mrseeker, nil
+}
+
+// NewFromGlob opens the files that match the filepath.Glob pattern, in
+// lexicographic order, which suits rotated log files like app.log.1,
+// app.log.2, etc. It returns ErrNoChildren if no files match.
+func NewFromGlob(pattern string) (*MultiReadSeeker, error) {
+	return NewFromGlobSorted(pattern, func(a, b string) bool {
+		return a < b
+	})
+}
+
+// NewFromGlobSorted is like NewFromGlob, but the files are put in the
+// order given by the less function.
+func NewFromGlobSorted(pattern string, less func(a, b string) bool) (*MultiReadSeeker, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Matching %s", pattern)
+	}
+	if len(paths) == 0 {
+		return nil, errors.Wrapf(ErrNoChildren, "No files match %s", pattern)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return less(paths[i], paths[j])
+	})
+	return NewFromFiles(paths...)
+}
+
+// NewFromDirectory opens the regular files in dir whose names match the
+// filepath.Match pattern, sorted by name. If pattern is "" or "*", all
+// the regular files are opened. Subdirectories are skipped, and so are
+// symlinks, unless the WithFollowSymlinks option is given. It returns
+// ErrNoChildren if no files match.
+func NewFromDirectory(dir, pattern string, opts ...Option) (*MultiReadSeeker, error) {
+	o := newOptions(opts)
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	// The entries are sorted by filename
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Reading directory %s", dir)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		matched, err := filepath.Match(pattern, entry.Name())
+		if err != nil {
+			return nil, errors.Wrapf(err, "Matching %s", pattern)
+		}
+		if !matched {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+
+		mode := entry.Type()
+		if mode&os.ModeSymlink != 0 && o.followSymlinks {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Following symlink %s", path)
+			}
+			mode = info.Mode()
+		}
+		if mode.IsRegular() {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, errors.Wrapf(ErrNoChildren, "No files in %s match %s",
+			dir, pattern)
+	}
+	return NewFromFiles(paths...)
+}
+
+// NewFromBytes returns a MultiReadSeeker that reads the byte slices as
+// one stream. Empty slices are skipped, as with any other empty child.
+func NewFromBytes(slices ...[]byte) (*MultiReadSeeker, error) {
+	return NewFromBytesWithOptions(nil, slices...)
+}
+
+// NewFromBytesWithOptions is NewFromBytes with options, as for
+// NewWithOptions; with WithEmptyChildPolicy(ErrorOnEmpty), an empty
+// slice is an error.
+func NewFromBytesWithOptions(opts []Option, slices ...[]byte) (*MultiReadSeeker, error) {
+	children := make([]ReadCloseSeeker, len(slices))
+	for i, slice := range slices {
+		children[i] = BytesChild(slice)
+	}
+	return NewWithOptions(opts, children...)
+}
+
+// NewFromStrings returns a MultiReadSeeker that reads the strings as
+// one stream. Empty strings are skipped, as with any other empty child.
+func NewFromStrings(strs ...string) (*MultiReadSeeker, error) {
+	children := make([]ReadCloseSeeker, len(strs))
+	for i, str := range strs {
+		children[i] = StringChild(str)
+	}
+	return New(children...)
+}
+
+// NewFromReaders reads each of the readers to its end, so that readers
+// that can't seek, like a gzip.Reader or an HTTP response body, can be
+// children. Small readers are kept in memory, and larger ones are
+// written to temporary files, which are removed when the
+// MultiReadSeeker is closed.
+func NewFromReaders(readers ...io.Reader) (*MultiReadSeeker, error) {
+	return NewFromReadersWithOptions(nil, readers...)
+}
+
+// NewFromReadersWithOptions is NewFromReaders with options; WithMaxMemory
+// sets the size above which a reader is buffered in a temporary file.
+func NewFromReadersWithOptions(opts []Option, readers ...io.Reader) (*MultiReadSeeker, error) {
+	o := newOptions(opts)
+	children := make([]ReadCloseSeeker, 0, len(readers))
+	for i, reader := range readers {
+		child, err := bufferReader(reader, o.maxMemory)
+		if err != nil {
+			closeAll(children)
+			return nil, errors.Wrapf(err, "Buffering reader #%d (0-based)", i)
+		}
+		children = append(children, child)
+	}
+
+	mrseeker, err := New(children...)
+	if err != nil {
+		closeAll(children)
+		return nil, err
+	}
+	return mrseeker, nil
+}
+
+// Read all of reader into memory, or into a temporary file if it has
+// more than maxMemory bytes.
+func bufferReader(reader io.Reader, maxMemory int64) (ReadCloseSeeker, error) {
+	var buf bytes.Buffer
+	// One byte more than maxMemory shows that the reader is too big
+	limit := maxMemory
+	if limit < math.MaxInt64 {
+		limit++
+	}
+	n, err := io.CopyN(&buf, reader, limit)
+	if err == io.EOF && n <= maxMemory {
+		return NopCloser(bytes.NewReader(buf.Bytes())), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Too big; put what we've read, and the rest, in a file
+	fh, err := os.CreateTemp("", "multireadseeker-")
+	if err != nil {
+		return nil, err
+	}
+	child := tempFile{fh}
+	_, err = buf.WriteTo(fh)
+	if err == nil {
+		_, err = io.Copy(fh, reader)
+	}
+	if err == nil {
+		_, err = fh.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		child.Close() // ignore any error
+		return nil, err
+	}
+	return child, nil
+}
+
+// A temporary file that is removed when it is closed
+type tempFile struct {
+	*os.File
+}
+
+func (self tempFile) Close() error {
+	err := self.File.Close()
+	removeErr := os.Remove(self.Name())
+	if err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// NopCloser returns a ReadCloseSeeker whose Close does nothing, for
+// an io.ReadSeeker like a *bytes.Reader or *strings.Reader. If r is an
+// io.ReaderAt, so is the ReadCloseSeeker.
+func NopCloser(r io.ReadSeeker) ReadCloseSeeker {
+	if readerAt, ok := r.(io.ReaderAt); ok {
+		return nopCloserReaderAt{nopCloser{r}, readerAt}
+	}
+	return nopCloser{r}
+}
+
+// BytesChild returns a child that reads data.
+func BytesChild(data []byte) ReadCloseSeeker {
+	return NopCloser(bytes.NewReader(data))
+}
+
+// StringChild returns a child that reads s.
+func StringChild(s string) ReadCloseSeeker {
+	return NopCloser(strings.NewReader(s))
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (self nopCloser) Close() error {
+	return nil
+}
+
+type nopCloserReaderAt struct {
+	nopCloser
+	io.ReaderAt
+}
+
+// Close the children after an error; the errors from closing them
+// are less interesting than the original error, so they are ignored.
+func closeAll(children []ReadCloseSeeker) {
+	for _, child := range children {
+		child.Close()
+	}
+}
@@ -0,0 +1,340 @@
+package multireadseeker
+
+import (
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing/iotest"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestNewFromFiles(c *C) {
+	names := s.writeDataFiles(c, "newfromfiles", "ABC", "DEFG", "HI")
+	mrseeker, err := NewFromFiles(names...)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.NumChildren(), Equals, 3)
+
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFGHI")
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestNewFromFilesMissing(c *C) {
+	names := s.writeDataFiles(c, "newfromfilesmissing", "ABC")
+	missing := filepath.Join(s.tmpDir, "newfromfiles-does-not-exist")
+	_, err := NewFromFiles(names[0], missing)
+	c.Check(os.IsNotExist(errors.Cause(err)), Equals, true)
+	c.Check(err, ErrorMatches, ".*"+missing+".*")
+}
+
+func (s *MySuite) TestNewFromFilesOpenFails(c *C) {
+	names := s.writeDataFiles(c, "newfromfilesopen", "A", "B", "C", "D", "E")
+
+	// The third file fails to open
+	errOpen := errors.New("open failed")
+	var opened []*seekOnlyChild
+	savedOpenFile := openFile
+	defer func() { openFile = savedOpenFile }()
+	openFile = func(name string) (ReadCloseSeeker, error) {
+		if name == names[2] {
+			return nil, errOpen
+		}
+		child := newSeekOnlyChild(name)
+		opened = append(opened, child)
+		return child, nil
+	}
+
+	mrseeker, err := NewFromFiles(names...)
+	c.Check(mrseeker, IsNil)
+	c.Check(errors.Is(err, errOpen), Equals, true)
+	c.Check(err, ErrorMatches, ".*"+names[2]+".*")
+
+	// The first two were opened, and closed again
+	c.Assert(opened, HasLen, 2)
+	for _, child := range opened {
+		c.Check(child.closeCalls, Equals, 1)
+	}
+}
+
+func (s *MySuite) TestNewFromGlob(c *C) {
+	// Written out of order
+	dir := filepath.Join(s.tmpDir, "glob")
+	err := os.Mkdir(dir, 0775)
+	c.Assert(err, IsNil)
+	for name, content := range map[string]string{
+		"app.log.2":  "C",
+		"app.log.10": "B",
+		"app.log.1":  "A",
+		"other.log":  "X",
+	} {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0664)
+		c.Assert(err, IsNil)
+	}
+
+	mrseeker, err := NewFromGlob(filepath.Join(dir, "app.log.*"))
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABC")
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+
+	// Sort by the numeric suffix
+	suffix := func(name string) int {
+		n, err := strconv.Atoi(name[strings.LastIndex(name, ".")+1:])
+		c.Assert(err, IsNil)
+		return n
+	}
+	mrseeker, err = NewFromGlobSorted(filepath.Join(dir, "app.log.*"),
+		func(a, b string) bool {
+			return suffix(a) < suffix(b)
+		})
+	c.Assert(err, IsNil)
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ACB")
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+
+	_, err = NewFromGlob(filepath.Join(dir, "nothing.*"))
+	c.Check(errors.Is(err, ErrNoChildren), Equals, true)
+
+	_, err = NewFromGlob("[")
+	c.Check(errors.Cause(err), Equals, filepath.ErrBadPattern)
+}
+
+func (s *MySuite) TestNewFromDirectory(c *C) {
+	dir := filepath.Join(s.tmpDir, "directory")
+	err := os.Mkdir(dir, 0775)
+	c.Assert(err, IsNil)
+	for name, content := range map[string]string{
+		"b.txt": "B",
+		"a.txt": "A",
+		"c.dat": "C",
+	} {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0664)
+		c.Assert(err, IsNil)
+	}
+	err = os.Mkdir(filepath.Join(dir, "d.txt"), 0775)
+	c.Assert(err, IsNil)
+	target := filepath.Join(s.tmpDir, "directory-link-target")
+	err = ioutil.WriteFile(target, []byte("L"), 0664)
+	c.Assert(err, IsNil)
+	err = os.Symlink(target, filepath.Join(dir, "e.txt"))
+	c.Assert(err, IsNil)
+
+	readAll := func(pattern string, opts ...Option) string {
+		mrseeker, err := NewFromDirectory(dir, pattern, opts...)
+		c.Assert(err, IsNil)
+		defer mrseeker.Close()
+		data, err := ioutil.ReadAll(mrseeker)
+		c.Assert(err, IsNil)
+		return string(data)
+	}
+	c.Check(readAll(""), Equals, "ABC")
+	c.Check(readAll("*"), Equals, "ABC")
+	c.Check(readAll("*.txt"), Equals, "AB")
+	c.Check(readAll("*.txt", WithFollowSymlinks()), Equals, "ABL")
+
+	_, err = NewFromDirectory(dir, "*.none")
+	c.Check(errors.Is(err, ErrNoChildren), Equals, true)
+	_, err = NewFromDirectory(dir, "[")
+	c.Check(errors.Cause(err), Equals, filepath.ErrBadPattern)
+}
+
+func (s *MySuite) TestNewFromBytes(c *C) {
+	mrseeker, err := NewFromBytes([]byte("ABC"), []byte{}, nil, []byte("DEFG"))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.NumChildren(), Equals, 2)
+	c.Check(mrseeker.Size(), Equals, int64(7))
+
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFG")
+
+	buf := make([]byte, 3)
+	n, err := mrseeker.ReadAt(buf, 2)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "CDE")
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+
+	_, err = NewFromBytes()
+	c.Check(errors.Is(err, ErrNoChildren), Equals, true)
+}
+
+func (s *MySuite) TestEmptyChildPolicy(c *C) {
+	errorOnEmpty := []Option{WithEmptyChildPolicy(ErrorOnEmpty)}
+	mrseeker, err := NewFromBytesWithOptions(errorOnEmpty,
+		[]byte("ABC"), []byte{}, []byte("DEFG"))
+	c.Check(mrseeker, IsNil)
+	c.Check(errors.Is(err, ErrEmptyChild), Equals, true)
+	c.Check(err, ErrorMatches, "io.Seeker #1 \\(0-based\\): child is empty")
+
+	// SkipEmpty is the default
+	mrseeker, err = NewFromBytesWithOptions(
+		[]Option{WithEmptyChildPolicy(SkipEmpty)}, []byte("ABC"), nil)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.NumChildren(), Equals, 1)
+
+	// Nothing is added
+	mrseeker, err = NewFromBytesWithOptions(errorOnEmpty, []byte("ABC"))
+	c.Assert(err, IsNil)
+	err = mrseeker.Append(StringChild("DEF"), StringChild(""))
+	c.Check(errors.Is(err, ErrEmptyChild), Equals, true)
+	err = mrseeker.Insert(0, StringChild(""))
+	c.Check(errors.Is(err, ErrEmptyChild), Equals, true)
+	err = mrseeker.Replace(0, StringChild(""))
+	c.Check(errors.Is(err, ErrEmptyChild), Equals, true)
+	c.Check(mrseeker.NumChildren(), Equals, 1)
+	data, err := mrseeker.ReadAll()
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABC")
+	c.Check(mrseeker.emptyChildren, HasLen, 0)
+
+	_, err = NewLazyWithOptions(errorOnEmpty, []LazyChild{{Size: 0}})
+	c.Check(errors.Is(err, ErrEmptyChild), Equals, true)
+}
+
+func (s *MySuite) TestNewFromStrings(c *C) {
+	mrseeker, err := NewFromStrings("hello", " ", "world")
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "hello world")
+
+	mrseeker, err = NewFromStrings("", "a\x00b", "", "\x00")
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.NumChildren(), Equals, 2)
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(data, DeepEquals, []byte{'a', 0, 'b', 0})
+}
+
+func (s *MySuite) TestNewFromReaders(c *C) {
+	// io.MultiReader can't seek
+	mrseeker, err := NewFromReaders(
+		io.MultiReader(strings.NewReader("ABC")),
+		io.MultiReader(strings.NewReader("")),
+		io.MultiReader(strings.NewReader("DEFG")))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.NumChildren(), Equals, 2)
+	child, err := mrseeker.ChildAt(0)
+	c.Assert(err, IsNil)
+	_, isTempFile := child.(tempFile)
+	c.Check(isTempFile, Equals, false)
+
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFG")
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestNewFromReadersTempFiles(c *C) {
+	// "ABC" fits in memory; "DEFG" goes to a temp file
+	mrseeker, err := NewFromReadersWithOptions([]Option{WithMaxMemory(3)},
+		io.MultiReader(strings.NewReader("ABC")),
+		io.MultiReader(strings.NewReader("DEFG")))
+	c.Assert(err, IsNil)
+	child, err := mrseeker.ChildAt(0)
+	c.Assert(err, IsNil)
+	_, isTempFile := child.(tempFile)
+	c.Check(isTempFile, Equals, false)
+	child, err = mrseeker.ChildAt(1)
+	c.Assert(err, IsNil)
+	tmp, isTempFile := child.(tempFile)
+	c.Assert(isTempFile, Equals, true)
+	name := tmp.Name()
+
+	buf := make([]byte, 4)
+	n, err := mrseeker.ReadAt(buf, 2)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "CDEF")
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFG")
+
+	// Closing removes the temp file
+	_, err = os.Stat(name)
+	c.Assert(err, IsNil)
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+	_, err = os.Stat(name)
+	c.Check(os.IsNotExist(err), Equals, true)
+
+	// No limit
+	mrseeker, err = NewFromReadersWithOptions(
+		[]Option{WithMaxMemory(math.MaxInt64)},
+		io.MultiReader(strings.NewReader("ABC")))
+	c.Assert(err, IsNil)
+	child, err = mrseeker.ChildAt(0)
+	c.Assert(err, IsNil)
+	_, isTempFile = child.(tempFile)
+	c.Check(isTempFile, Equals, false)
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABC")
+	c.Assert(mrseeker.Close(), IsNil)
+}
+
+func (s *MySuite) TestNewFromReadersError(c *C) {
+	errRead := errors.New("read failed")
+	_, err := NewFromReaders(strings.NewReader("ABC"),
+		io.MultiReader(strings.NewReader("DEF"), iotest.ErrReader(errRead)))
+	c.Check(errors.Is(err, errRead), Equals, true)
+	c.Check(err, ErrorMatches, ".*#1.*")
+
+	_, err = NewFromReaders()
+	c.Check(errors.Is(err, ErrNoChildren), Equals, true)
+}
+
+func (s *MySuite) TestNopCloser(c *C) {
+	child := NopCloser(strings.NewReader("ABCDEF"))
+	_, ok := child.(io.ReaderAt)
+	c.Check(ok, Equals, true)
+	_, err := child.Seek(2, io.SeekStart)
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(child)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "CDEF")
+	c.Check(child.Close(), IsNil)
+
+	// Not an io.ReaderAt
+	child = NopCloser(newSeekOnlyChild("GHI"))
+	_, ok = child.(io.ReaderAt)
+	c.Check(ok, Equals, false)
+
+	mrseeker, err := New(NopCloser(strings.NewReader("ABC")), child)
+	c.Assert(err, IsNil)
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCGHI")
+	c.Assert(mrseeker.Close(), IsNil)
+}
+
+func (s *MySuite) TestBytesAndStringChild(c *C) {
+	mrseeker, err := New(BytesChild([]byte("ABC")), StringChild(""),
+		StringChild("DEF"))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.NumChildren(), Equals, 2)
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEF")
+
+	child, err := mrseeker.ChildAt(1)
+	c.Assert(err, IsNil)
+	_, ok := child.(io.ReaderAt)
+	c.Check(ok, Equals, true)
+	c.Assert(mrseeker.Close(), IsNil)
+}
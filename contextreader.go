@@ -0,0 +1,46 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Reading with a context for one operation.
+
+import (
+	"context"
+	"io"
+)
+
+type contextReader struct {
+	parent *MultiReadSeeker
+	ctx    context.Context
+	closed bool
+}
+
+// ContextReader returns an io.ReadCloser that reads from the
+// MultiReadSeeker, at its position, until ctx is done; then Read
+// returns ctx.Err(). Unlike WithContext, the context is only for the
+// reads done through the io.ReadCloser. Closing it doesn't close the
+// MultiReadSeeker.
+func (self *MultiReadSeeker) ContextReader(ctx context.Context) io.ReadCloser {
+	return &contextReader{
+		parent: self,
+		ctx:    ctx,
+	}
+}
+
+func (self *contextReader) Read(p []byte) (int, error) {
+	if self.closed {
+		return 0, ErrClosedSeeker
+	}
+	err := self.ctx.Err()
+	if err != nil {
+		return 0, err
+	}
+	return self.parent.Read(p)
+}
+
+func (self *contextReader) Close() error {
+	if self.closed {
+		return ErrAlreadyClosed
+	}
+	self.closed = true
+	return nil
+}
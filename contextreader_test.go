@@ -0,0 +1,35 @@
+package multireadseeker
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestContextReader(c *C) {
+	mrseeker, err := NewFromStrings("ABC", "DEF")
+	c.Assert(err, IsNil)
+	ctx, cancel := context.WithCancel(context.Background())
+	reader := mrseeker.ContextReader(ctx)
+
+	buf := make([]byte, 2)
+	n, err := reader.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "AB")
+
+	cancel()
+	n, err = reader.Read(buf)
+	c.Check(n, Equals, 0)
+	c.Check(err, Equals, context.Canceled)
+
+	// The MultiReadSeeker can still be read, and isn't closed by
+	// closing the reader
+	c.Assert(reader.Close(), IsNil)
+	_, err = reader.Read(buf)
+	c.Check(errors.Is(err, ErrClosedSeeker), Equals, true)
+	n, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "CD")
+	c.Assert(mrseeker.Close(), IsNil)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Counting the I/O on a child.
+
+import (
+	"sync"
+)
+
+// ChildStats is a snapshot of the counters of a CountingReadCloseSeeker.
+type ChildStats struct {
+	BytesRead  int64
+	ReadCalls  int64
+	SeekCalls  int64
+	CloseCalls int64
+}
+
+// CountingReadCloseSeeker wraps a child and counts the calls to it,
+// and the bytes read from it. It doesn't pass on io.ReaderAt, so
+// MultiReadSeeker.ReadAt reads it with Seek and Read, which are
+// counted.
+type CountingReadCloseSeeker struct {
+	child ReadCloseSeeker
+
+	mu    sync.Mutex
+	stats ChildStats
+}
+
+// NewCountingReadCloseSeeker wraps child in a CountingReadCloseSeeker.
+func NewCountingReadCloseSeeker(child ReadCloseSeeker) *CountingReadCloseSeeker {
+	return &CountingReadCloseSeeker{
+		child: child,
+	}
+}
+
+func (self *CountingReadCloseSeeker) Read(p []byte) (int, error) {
+	n, err := self.child.Read(p)
+	self.mu.Lock()
+	self.stats.ReadCalls++
+	self.stats.BytesRead += int64(n)
+	self.mu.Unlock()
+	return n, err
+}
+
+func (self *CountingReadCloseSeeker) Seek(offset int64, whence int) (int64, error) {
+	self.mu.Lock()
+	self.stats.SeekCalls++
+	self.mu.Unlock()
+	return self.child.Seek(offset, whence)
+}
+
+func (self *CountingReadCloseSeeker) Close() error {
+	self.mu.Lock()
+	self.stats.CloseCalls++
+	self.mu.Unlock()
+	return self.child.Close()
+}
+
+// Counters returns a snapshot of the counters. It can be called while
+// the child is being used.
+func (self *CountingReadCloseSeeker) Counters() ChildStats {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.stats
+}
@@ -0,0 +1,32 @@
+package multireadseeker
+
+import (
+	"io"
+	"io/ioutil"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestCountingReadCloseSeeker(c *C) {
+	child1 := NewCountingReadCloseSeeker(StringChild("ABC"))
+	child2 := NewCountingReadCloseSeeker(StringChild("DEFG"))
+	mrseeker, err := New(child1, child2)
+	c.Assert(err, IsNil)
+	// Measuring each child seeks to its end and back
+	c.Check(child1.Counters(), Equals, ChildStats{SeekCalls: 2})
+	c.Check(child2.Counters(), Equals, ChildStats{SeekCalls: 2})
+
+	_, err = mrseeker.Seek(4, io.SeekStart)
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "EFG")
+	c.Check(child1.Counters().BytesRead, Equals, int64(0))
+	c.Check(child2.Counters().BytesRead, Equals, int64(3))
+	c.Check(child2.Counters().SeekCalls, Equals, int64(3))
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+	c.Check(child1.Counters().CloseCalls, Equals, int64(1))
+	c.Check(child2.Counters().CloseCalls, Equals, int64(1))
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Decoding the MultiReadSeeker with the standard library's decoders.
+// The JSON and CSV decoders read ahead into their own buffers, so after
+// the MultiReadSeeker is seeked, they miss bytes or read some again.
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+)
+
+// JSONDecoder returns a json.Decoder that reads from the
+// MultiReadSeeker, from its position, as for JSON Lines files that
+// were rotated into several children.
+func (self *MultiReadSeeker) JSONDecoder() *json.Decoder {
+	return json.NewDecoder(self)
+}
+
+// XMLDecoder returns an xml.Decoder that reads from the
+// MultiReadSeeker, from its position. Since the MultiReadSeeker is an
+// io.ByteReader, the decoder reads it a byte at a time, without a
+// buffer of its own.
+func (self *MultiReadSeeker) XMLDecoder() *xml.Decoder {
+	return xml.NewDecoder(self)
+}
+
+// CSVReader returns a csv.Reader that reads from the MultiReadSeeker,
+// from its position, as for a CSV file that was split into several
+// children. A header row at the start of any child but the first is
+// read as a record; the caller must skip it.
+func (self *MultiReadSeeker) CSVReader() *csv.Reader {
+	return csv.NewReader(self)
+}
@@ -0,0 +1,51 @@
+package multireadseeker
+
+import (
+	"encoding/xml"
+	"io"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestJSONDecoder(c *C) {
+	// An object can span children
+	mrseeker, err := NewFromStrings("{\"n\": 1}\n{\"n\"", ": 2}\n", "{\"n\": 3}\n")
+	c.Assert(err, IsNil)
+	decoder := mrseeker.JSONDecoder()
+	var values []int
+	for {
+		var record struct{ N int }
+		err = decoder.Decode(&record)
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, IsNil)
+		values = append(values, record.N)
+	}
+	c.Check(values, DeepEquals, []int{1, 2, 3})
+}
+
+func (s *MySuite) TestXMLDecoder(c *C) {
+	mrseeker, err := NewFromStrings("<items><item>one</it", "em><item>two</item></items>")
+	c.Assert(err, IsNil)
+	var items struct {
+		XMLName xml.Name `xml:"items"`
+		Items   []string `xml:"item"`
+	}
+	err = mrseeker.XMLDecoder().Decode(&items)
+	c.Assert(err, IsNil)
+	c.Check(items.Items, DeepEquals, []string{"one", "two"})
+}
+
+func (s *MySuite) TestCSVReader(c *C) {
+	mrseeker, err := NewFromStrings("name,count\napple,1\nbanana,", "2\ncherry,3\n")
+	c.Assert(err, IsNil)
+	records, err := mrseeker.CSVReader().ReadAll()
+	c.Assert(err, IsNil)
+	c.Check(records, DeepEquals, [][]string{
+		{"name", "count"},
+		{"apple", "1"},
+		{"banana", "2"},
+		{"cherry", "3"},
+	})
+}
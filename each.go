@@ -0,0 +1,149 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Calling a function for each child.
+
+import (
+	"time"
+)
+
+// ForEachChild calls fn with each child, in order, opening the ones
+// from NewLazy that aren't open yet. If fn returns an error, it stops,
+// and returns that error. fn may read or seek the child; afterwards,
+// the current child is put back where Read expects it, so the position
+// doesn't change.
+func (self *MultiReadSeeker) ForEachChild(fn func(i int, child ReadCloseSeeker) error) error {
+	if self.closed {
+		return ErrAlreadyClosed
+	}
+	var err error
+	for i := range self.children {
+		var child ReadCloseSeeker
+		child, err = self.child(i)
+		if err != nil {
+			break
+		}
+		err = fn(i, child)
+		if err != nil {
+			break
+		}
+	}
+	restoreErr := self.restoreChildPosition()
+	if err == nil {
+		err = restoreErr
+	}
+	return err
+}
+
+// MapChildren returns a new MultiReadSeeker whose children are the
+// children of this one passed through fn, as to wrap each one in a
+// CountingReadCloseSeeker. The new children are measured, so fn may
+// change their sizes, unless WithLazyMap is given; the other options
+// are added to this MultiReadSeeker's options. Children that fn makes
+// empty are left out, and children that have failed are kept, as
+// failed. As with Clone, the children are shared, this MultiReadSeeker
+// isn't changed, and closing the new one doesn't close its children.
+func (self *MultiReadSeeker) MapChildren(fn func(ReadCloseSeeker) ReadCloseSeeker,
+	opts ...Option) (*MultiReadSeeker, error) {
+	if self.closed {
+		return nil, ErrAlreadyClosed
+	}
+	if self.shared == nil {
+		self.shared = &sharedChildren{positioner: self}
+	}
+	mapped := &MultiReadSeeker{
+		initialized: true,
+		created:     time.Now(),
+		options:     self.options,
+		borrowed:    true,
+		shared:      self.shared,
+	}
+	for _, opt := range opts {
+		opt(&mapped.options)
+	}
+
+	for i := range self.children {
+		size := self.superPosEnd[i] - self.superPosStart[i]
+		switch {
+		case self.failed[i]:
+			mapped.appendChild(nil, size, nil)
+			mapped.failed[len(mapped.failed)-1] = true
+		case mapped.options.lazyMap:
+			i := i
+			mapped.appendChild(nil, size, func() (ReadCloseSeeker, error) {
+				child, err := self.child(i)
+				if err != nil {
+					return nil, err
+				}
+				return fn(child), nil
+			})
+		default:
+			child, err := self.child(i)
+			if err != nil {
+				return nil, err
+			}
+			child = fn(child)
+			size, err = measureChild(child)
+			if err != nil {
+				return nil, err
+			}
+			if size == 0 {
+				continue
+			}
+			mapped.appendChild(mapped.wrapChild(child), size, nil)
+		}
+		mapped.names[len(mapped.names)-1] = self.names[i]
+	}
+
+	// Measuring the new children moved ours
+	err := self.restoreChildPosition()
+	if err != nil {
+		return nil, err
+	}
+	return mapped, nil
+}
+
+// FilterChildren returns a new MultiReadSeeker with only the children
+// for which fn returns true, in the same order, as to leave out the
+// children whose names don't match a pattern. Its positions count only
+// those children. Children from NewLazy are opened, so that fn can look
+// at them, and children that have failed are left out. As with Clone,
+// the children are shared, this MultiReadSeeker isn't changed, and
+// closing the new one doesn't close its children.
+func (self *MultiReadSeeker) FilterChildren(fn func(i int, child ReadCloseSeeker) bool) (*MultiReadSeeker, error) {
+	if self.closed {
+		return nil, ErrAlreadyClosed
+	}
+	if self.shared == nil {
+		self.shared = &sharedChildren{positioner: self}
+	}
+	filtered := &MultiReadSeeker{
+		initialized: true,
+		created:     time.Now(),
+		options:     self.options,
+		borrowed:    true,
+		shared:      self.shared,
+	}
+
+	for i := range self.children {
+		if self.failed[i] {
+			continue
+		}
+		child, err := self.child(i)
+		if err != nil {
+			return nil, err
+		}
+		if !fn(i, child) {
+			continue
+		}
+		filtered.appendChild(child, self.superPosEnd[i]-self.superPosStart[i], nil)
+		filtered.names[len(filtered.names)-1] = self.names[i]
+	}
+
+	// fn may have read or seeked the children
+	err := self.restoreChildPosition()
+	if err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
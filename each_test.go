@@ -0,0 +1,136 @@
+package multireadseeker
+
+import (
+	"crypto/sha256"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestForEachChild(c *C) {
+	mrseeker, err := NewFromStrings("ABC", "DEF", "GHI")
+	c.Assert(err, IsNil)
+	_, err = mrseeker.Seek(4, io.SeekStart)
+	c.Assert(err, IsNil)
+
+	var sums [][32]byte
+	err = mrseeker.ForEachChild(func(i int, child ReadCloseSeeker) error {
+		_, err := child.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, child)
+		var sum [32]byte
+		copy(sum[:], h.Sum(nil))
+		sums = append(sums, sum)
+		return err
+	})
+	c.Assert(err, IsNil)
+	c.Check(sums, DeepEquals, [][32]byte{sha256.Sum256([]byte("ABC")),
+		sha256.Sum256([]byte("DEF")), sha256.Sum256([]byte("GHI"))})
+
+	// The position didn't change
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "EFGHI")
+
+	errStop := errors.New("stop")
+	var visited []int
+	err = mrseeker.ForEachChild(func(i int, child ReadCloseSeeker) error {
+		visited = append(visited, i)
+		if i == 1 {
+			return errStop
+		}
+		return nil
+	})
+	c.Check(err, Equals, errStop)
+	c.Check(visited, DeepEquals, []int{0, 1})
+}
+
+func (s *MySuite) TestMapChildren(c *C) {
+	mrseeker, err := NewFromNamedChildren(
+		NamedChild{"first", StringChild("ABCD")},
+		NamedChild{"second", StringChild("EF")},
+		NamedChild{"third", StringChild("GHIJ")})
+	c.Assert(err, IsNil)
+
+	// The sizes can change
+	limited, err := mrseeker.MapChildren(func(child ReadCloseSeeker) ReadCloseSeeker {
+		return LimitedReadCloseSeeker(child, 3)
+	})
+	c.Assert(err, IsNil)
+	c.Check(limited.ChildSizes(), DeepEquals, []int64{3, 2, 3})
+	c.Check(limited.ChildNames(), DeepEquals, []string{"first", "second", "third"})
+	data, err := ioutil.ReadAll(limited)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCEFGHI")
+
+	// The original isn't changed
+	c.Check(mrseeker.Size(), Equals, int64(10))
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFGHIJ")
+	c.Assert(limited.Close(), IsNil)
+}
+
+func (s *MySuite) TestMapChildrenLazy(c *C) {
+	mrseeker, err := NewFromStrings("ABC", "DEF")
+	c.Assert(err, IsNil)
+	var counters []*CountingReadCloseSeeker
+	counted, err := mrseeker.MapChildren(func(child ReadCloseSeeker) ReadCloseSeeker {
+		counter := NewCountingReadCloseSeeker(child)
+		counters = append(counters, counter)
+		return counter
+	}, WithLazyMap())
+	c.Assert(err, IsNil)
+	c.Check(counters, HasLen, 0)
+
+	buf := make([]byte, 2)
+	_, err = counted.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(counters, HasLen, 1)
+	data, err := ioutil.ReadAll(counted)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "CDEF")
+	c.Assert(counters, HasLen, 2)
+	c.Check(counters[0].Counters().BytesRead, Equals, int64(3))
+	c.Check(counters[1].Counters().BytesRead, Equals, int64(3))
+}
+
+func (s *MySuite) TestFilterChildren(c *C) {
+	mrseeker, err := NewFromNamedChildren(
+		NamedChild{"a.log", StringChild("ABC")},
+		NamedChild{"b.txt", StringChild("DEF")},
+		NamedChild{"c.log", StringChild("GHIJ")})
+	c.Assert(err, IsNil)
+
+	// Read some of the original first; the filter doesn't move it
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(mrseeker, buf)
+	c.Assert(err, IsNil)
+
+	names := mrseeker.ChildNames()
+	logs, err := mrseeker.FilterChildren(func(i int, child ReadCloseSeeker) bool {
+		return strings.HasSuffix(names[i], ".log")
+	})
+	c.Assert(err, IsNil)
+	c.Check(logs.Size(), Equals, int64(7))
+	c.Check(logs.ChildNames(), DeepEquals, []string{"a.log", "c.log"})
+	start, err := logs.ChildStartPos(1)
+	c.Assert(err, IsNil)
+	c.Check(start, Equals, int64(3))
+	data, err := ioutil.ReadAll(logs)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCGHIJ")
+
+	c.Check(mrseeker.NumChildren(), Equals, 3)
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "EFGHIJ")
+	c.Assert(logs.Close(), IsNil)
+	c.Assert(mrseeker.Close(), IsNil)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Keeping files open for ConcatFile, for WithMaxOpenFiles.
+
+import (
+	"container/list"
+	"os"
+)
+
+// The files that a ConcatFile keeps open besides its current file, up
+// to max of them. When another one is added, the one that was used
+// least recently is closed. A nil *fileCache keeps no files open.
+type fileCache struct {
+	max int
+
+	// Most recently used at the front; the values are *cachedFile
+	order *list.List
+	files map[int]*list.Element
+}
+
+type cachedFile struct {
+	fileNum int
+	fh      *os.File
+}
+
+func newFileCache(max int) *fileCache {
+	return &fileCache{
+		max:   max,
+		order: list.New(),
+		files: make(map[int]*list.Element),
+	}
+}
+
+// Remove the file from the cache, and return it, or nil if it isn't in
+// the cache. It is no longer counted; the caller gives it back with put.
+func (self *fileCache) take(fileNum int) *os.File {
+	if self == nil {
+		return nil
+	}
+	elem, ok := self.files[fileNum]
+	if !ok {
+		return nil
+	}
+	self.order.Remove(elem)
+	delete(self.files, fileNum)
+	return elem.Value.(*cachedFile).fh
+}
+
+// Close the least recently used file if the cache is full, so that a
+// file besides the current one can be opened without going over the
+// limit.
+func (self *fileCache) makeRoom() {
+	if self == nil {
+		return
+	}
+	for self.order.Len() > 0 && self.order.Len() >= self.max {
+		self.evict()
+	}
+}
+
+// Keep the file open, as the most recently used one, closing the least
+// recently used one if the cache is full. Without room for any files,
+// the file is closed. If the file was already kept open with another
+// handle, that handle is closed.
+func (self *fileCache) put(fileNum int, fh *os.File) error {
+	if self == nil || self.max == 0 {
+		return fh.Close()
+	}
+	if old := self.take(fileNum); old != nil && old != fh {
+		old.Close() // ignore any error; nothing is reading it
+	}
+	self.makeRoom()
+	self.files[fileNum] = self.order.PushFront(&cachedFile{fileNum, fh})
+	return nil
+}
+
+// Close the least recently used file. Nothing is reading it, so an
+// error from Close doesn't matter.
+func (self *fileCache) evict() {
+	elem := self.order.Back()
+	self.order.Remove(elem)
+	cached := elem.Value.(*cachedFile)
+	if self.files[cached.fileNum] == elem {
+		delete(self.files, cached.fileNum)
+	}
+	cached.fh.Close() // ignore any error
+}
+
+// Close all the files, returning the first error
+func (self *fileCache) closeAll() error {
+	if self == nil {
+		return nil
+	}
+	var firstErr error
+	for elem := self.order.Front(); elem != nil; elem = elem.Next() {
+		err := elem.Value.(*cachedFile).fh.Close()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	self.order.Init()
+	self.files = make(map[int]*list.Element)
+	return firstErr
+}
+
+// The number of files in the cache
+func (self *fileCache) len() int {
+	if self == nil {
+		return 0
+	}
+	return self.order.Len()
+}
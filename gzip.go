@@ -0,0 +1,257 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Decompressing a gzip'ed child.
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// A GzipOption changes how GzipDecompressChild works.
+type GzipOption func(*gzipChild)
+
+// WithGzipCheckpointInterval makes the child remember where it can
+// start decompressing again, at most every n decompressed bytes, so
+// that a Seek backwards doesn't have to decompress from the start. A
+// gzip stream can only be started again at the start of one of its
+// members, so this helps only with files that have many members, as
+// from bgzip or pigz --independent; a file with one member still
+// starts again from the start.
+func WithGzipCheckpointInterval(n int64) GzipOption {
+	return func(self *gzipChild) {
+		self.interval = n
+	}
+}
+
+// WithKnownDecompressedSize gives the size of the decompressed data,
+// so that Seek(0, io.SeekEnd), as New uses to find the size of each
+// child, doesn't decompress all of it to find out. If n is wrong, so
+// are the positions of the children after this one.
+func WithKnownDecompressedSize(n int64) GzipOption {
+	return func(self *gzipChild) {
+		self.size = n
+	}
+}
+
+type gzipChild struct {
+	child ReadCloseSeeker
+	br    *gzipByteReader
+	z     *gzip.Reader
+
+	// The position in the decompressed data that z is at, and the one
+	// that Seek moved to; Read catches up
+	pos    int64
+	seekTo int64
+
+	// The decompressed size; -1 until it is known
+	size int64
+
+	// z has returned the end of the last member
+	atEnd bool
+
+	// Where decompressing can start again, in order; the first is
+	// the start
+	interval    int64
+	checkpoints []gzipCheckpoint
+}
+
+// The start of a gzip member
+type gzipCheckpoint struct {
+	compressedPos int64
+	pos           int64
+}
+
+// gzip.Reader reads from an io.ByteReader one byte at a time, without
+// reading past the end of a member, so the compressed position of the
+// next member is known.
+type gzipByteReader struct {
+	*bufio.Reader
+	compressedPos int64
+}
+
+func (self *gzipByteReader) Read(p []byte) (int, error) {
+	n, err := self.Reader.Read(p)
+	self.compressedPos += int64(n)
+	return n, err
+}
+
+func (self *gzipByteReader) ReadByte() (byte, error) {
+	b, err := self.Reader.ReadByte()
+	if err == nil {
+		self.compressedPos++
+	}
+	return b, err
+}
+
+// GzipDecompressChild returns a child that reads the decompressed data
+// of the gzip'ed data in r, from its current position. A gzip.Reader
+// can't seek, so the next Read after a Seek forwards decompresses up to
+// the new position, and after a Seek backwards, decompresses again from
+// the start, or from a checkpoint; see WithGzipCheckpointInterval.
+// Seeks past the end go to the end. Unless WithKnownDecompressedSize is
+// given, the size is only known once all of the data has been
+// decompressed, so Seek(0, io.SeekEnd) does that. It returns an error
+// if r doesn't start with a gzip header. Close closes r.
+func GzipDecompressChild(r ReadCloseSeeker, opts ...GzipOption) (ReadCloseSeeker, error) {
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	self := &gzipChild{
+		child:       r,
+		br:          &gzipByteReader{bufio.NewReader(r), start},
+		size:        -1,
+		checkpoints: []gzipCheckpoint{{start, 0}},
+	}
+	for _, opt := range opts {
+		opt(self)
+	}
+	self.z, err = gzip.NewReader(self.br)
+	if err != nil {
+		return nil, errors.Wrap(err, "Reading gzip header")
+	}
+	self.z.Multistream(false)
+	return self, nil
+}
+
+func (self *gzipChild) Read(p []byte) (int, error) {
+	if self.seekTo != self.pos {
+		err := self.catchUp()
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := self.read(p)
+	self.seekTo = self.pos
+	return n, err
+}
+
+func (self *gzipChild) read(p []byte) (int, error) {
+	for {
+		if self.atEnd {
+			return 0, io.EOF
+		}
+		n, err := self.z.Read(p)
+		self.pos += int64(n)
+		if err != io.EOF {
+			return n, err
+		}
+
+		// The end of a member; is there another?
+		compressedPos := self.br.compressedPos
+		err = self.z.Reset(self.br)
+		if err == io.EOF {
+			self.atEnd = true
+			self.size = self.pos
+		} else if err != nil {
+			return n, errors.Wrap(err, "Reading gzip header")
+		} else {
+			self.z.Multistream(false)
+			self.checkpoint(compressedPos)
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// Remember the start of a member, if it is far enough from the last
+// checkpoint
+func (self *gzipChild) checkpoint(compressedPos int64) {
+	if self.interval <= 0 {
+		return
+	}
+	last := self.checkpoints[len(self.checkpoints)-1]
+	if self.pos-last.pos >= self.interval {
+		self.checkpoints = append(self.checkpoints, gzipCheckpoint{compressedPos, self.pos})
+	}
+}
+
+func (self *gzipChild) Seek(offset int64, whence int) (int64, error) {
+	var newPos int64
+	switch whence {
+	case io.SeekStart:
+		newPos = offset
+	case io.SeekCurrent:
+		newPos = self.seekTo + offset
+	case io.SeekEnd:
+		if self.size < 0 {
+			// Decompress the rest to find the end
+			self.seekTo = self.pos
+			_, err := io.Copy(io.Discard, gzipReader{self})
+			if err != nil {
+				return self.seekTo, err
+			}
+		}
+		newPos = self.size + offset
+	default:
+		return self.seekTo, errors.Errorf(
+			"Seek(offset, whence); whence must be 0, 1, or 2, not %d", whence)
+	}
+	if newPos < 0 {
+		return self.seekTo, errors.Wrapf(ErrNegativeSeek, "Seek to %d", newPos)
+	}
+	if self.size >= 0 && newPos > self.size {
+		newPos = self.size
+	}
+	self.seekTo = newPos
+	return self.seekTo, nil
+}
+
+// Decompress up to where Seek moved to
+func (self *gzipChild) catchUp() error {
+	if self.seekTo < self.pos {
+		err := self.restart(self.seekTo)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := io.CopyN(io.Discard, gzipReader{self}, self.seekTo-self.pos)
+	if err == io.EOF {
+		// Past the end, which wasn't known at the time of the Seek
+		self.seekTo = self.pos
+		err = nil
+	}
+	return err
+}
+
+// Reads from z, where it is, as for io.Copy; gzipChild.Read would
+// first go where Seek moved to
+type gzipReader struct {
+	*gzipChild
+}
+
+func (self gzipReader) Read(p []byte) (int, error) {
+	return self.read(p)
+}
+
+// Start decompressing again from the last checkpoint before pos
+func (self *gzipChild) restart(pos int64) error {
+	i := len(self.checkpoints) - 1
+	for self.checkpoints[i].pos > pos {
+		i--
+	}
+	checkpoint := self.checkpoints[i]
+	_, err := self.child.Seek(checkpoint.compressedPos, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	self.br.Reset(self.child)
+	self.br.compressedPos = checkpoint.compressedPos
+	err = self.z.Reset(self.br)
+	if err != nil {
+		return errors.Wrap(err, "Reading gzip header")
+	}
+	self.z.Multistream(false)
+	self.pos = checkpoint.pos
+	self.atEnd = false
+	return nil
+}
+
+func (self *gzipChild) Close() error {
+	return self.child.Close()
+}
@@ -0,0 +1,108 @@
+package multireadseeker
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// Compress each of the parts as its own gzip member
+func gzipMembers(c *C, parts ...string) []byte {
+	var buf bytes.Buffer
+	for _, part := range parts {
+		w := gzip.NewWriter(&buf)
+		_, err := w.Write([]byte(part))
+		c.Assert(err, IsNil)
+		c.Assert(w.Close(), IsNil)
+	}
+	return buf.Bytes()
+}
+
+func (s *MySuite) TestGzipDecompressChild(c *C) {
+	var parts []string
+	for i := 0; i < 10; i++ {
+		parts = append(parts, strings.Repeat(fmt.Sprintf("%d", i), 100))
+	}
+	all := strings.Join(parts, "")
+	compressed := gzipMembers(c, parts...)
+
+	child, err := GzipDecompressChild(BytesChild(compressed))
+	c.Assert(err, IsNil)
+	mrseeker, err := New(StringChild("<"), child, StringChild(">"))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Size(), Equals, int64(1002))
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "<"+all+">")
+
+	// Backwards and forwards
+	buf := make([]byte, 4)
+	for _, pos := range []int64{950, 10, 398, 399, 0, 997} {
+		_, err = child.Seek(pos, io.SeekStart)
+		c.Assert(err, IsNil)
+		n, err := io.ReadFull(child, buf[:3])
+		c.Assert(err, IsNil)
+		c.Check(string(buf[:n]), Equals, all[pos:pos+3])
+	}
+	pos, err := child.Seek(5, io.SeekEnd)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(1000))
+	c.Assert(mrseeker.Close(), IsNil)
+
+	_, err = GzipDecompressChild(StringChild("not gzip"))
+	c.Check(err, ErrorMatches, "Reading gzip header: .*")
+}
+
+func (s *MySuite) TestGzipCheckpoints(c *C) {
+	var parts []string
+	for i := 0; i < 10; i++ {
+		parts = append(parts, strings.Repeat(fmt.Sprintf("%d", i), 100))
+	}
+	all := strings.Join(parts, "")
+
+	// The data doesn't have to start at the start of the child
+	compressed := append([]byte("header"), gzipMembers(c, parts...)...)
+	r := BytesChild(compressed)
+	_, err := r.Seek(6, io.SeekStart)
+	c.Assert(err, IsNil)
+
+	child, err := GzipDecompressChild(r, WithGzipCheckpointInterval(250),
+		WithKnownDecompressedSize(1000))
+	c.Assert(err, IsNil)
+	gz := child.(*gzipChild)
+	c.Check(gz.checkpoints, HasLen, 1)
+	end, err := child.Seek(0, io.SeekEnd)
+	c.Assert(err, IsNil)
+	c.Check(end, Equals, int64(1000))
+
+	// The size was known, so the Seek didn't decompress anything
+	c.Check(gz.pos, Equals, int64(0))
+	_, err = child.Seek(0, io.SeekStart)
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(child)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, all)
+
+	// Only the members at or after 250, 550 and 850 are checkpoints
+	var positions []int64
+	for _, checkpoint := range gz.checkpoints {
+		positions = append(positions, checkpoint.pos)
+	}
+	c.Check(positions, DeepEquals, []int64{0, 300, 600, 900})
+
+	// Seeking back starts from the last checkpoint before
+	_, err = child.Seek(650, io.SeekStart)
+	c.Assert(err, IsNil)
+	buf := make([]byte, 3)
+	_, err = io.ReadFull(child, buf)
+	c.Assert(err, IsNil)
+	c.Check(gz.br.compressedPos > gz.checkpoints[2].compressedPos, Equals, true)
+	c.Check(gz.br.compressedPos < gz.checkpoints[3].compressedPos, Equals, true)
+	c.Check(string(buf), Equals, "666")
+	c.Assert(child.Close(), IsNil)
+}
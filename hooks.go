@@ -0,0 +1,56 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Callbacks for events while reading.
+
+type hooks struct {
+	childSwitch []func(fromIndex, toIndex int)
+	eof         []func()
+	childErr    []func(childIdx int, err error)
+}
+
+// OnChildSwitch adds fn to the functions that are called when Read
+// moves from the end of child fromIndex to the start of child toIndex,
+// as for a progress bar. Each call adds another function; they are
+// called in the order they were added. They are called by Read, in
+// its goroutine, so they must return quickly, and must not use the
+// MultiReadSeeker.
+func (self *MultiReadSeeker) OnChildSwitch(fn func(fromIndex, toIndex int)) {
+	self.hooks.childSwitch = append(self.hooks.childSwitch, fn)
+}
+
+func (self *hooks) childSwitched(fromIndex, toIndex int) {
+	for _, fn := range self.childSwitch {
+		fn(fromIndex, toIndex)
+	}
+}
+
+// OnEOF adds fn to the functions that are called when Read reads the
+// last byte of the last child, as to tell a pipeline that the input is
+// done. They are called again only if Read reaches the end again,
+// after a Seek back. As with OnChildSwitch, they are called by Read.
+func (self *MultiReadSeeker) OnEOF(fn func()) {
+	self.hooks.eof = append(self.hooks.eof, fn)
+}
+
+// OnError adds fn to the functions that are called when a child fails
+// in Read, ReadAt, Seek, or Close, or a child from NewLazy can't be
+// opened. err is the error as the MultiReadSeeker reports it. The
+// functions are called before the error is returned, or before
+// WithErrorHandler is asked about it; they don't change what happens
+// to it. As with OnChildSwitch, they must return quickly.
+func (self *MultiReadSeeker) OnError(fn func(childIdx int, err error)) {
+	self.hooks.childErr = append(self.hooks.childErr, fn)
+}
+
+func (self *hooks) reachedEOF() {
+	for _, fn := range self.eof {
+		fn()
+	}
+}
+
+func (self *hooks) childError(childIdx int, err error) {
+	for _, fn := range self.childErr {
+		fn(childIdx, err)
+	}
+}
@@ -0,0 +1,96 @@
+package multireadseeker
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/crewjam/errset"
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestOnChildSwitch(c *C) {
+	mrseeker, err := NewFromStrings("ABC", "", "DEF", "GH")
+	c.Assert(err, IsNil)
+	var events []string
+	mrseeker.OnChildSwitch(func(fromIndex, toIndex int) {
+		events = append(events, fmt.Sprintf("1: %d->%d", fromIndex, toIndex))
+	})
+	mrseeker.OnChildSwitch(func(fromIndex, toIndex int) {
+		events = append(events, fmt.Sprintf("2: %d->%d", fromIndex, toIndex))
+	})
+
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFGH")
+	c.Check(events, DeepEquals, []string{"1: 0->1", "2: 0->1", "1: 1->2", "2: 1->2"})
+
+	// Seeking isn't a switch; reading past the end of the child is
+	events = nil
+	_, err = mrseeker.Seek(4, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Check(events, HasLen, 0)
+	_, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(events, DeepEquals, []string{"1: 1->2", "2: 1->2"})
+}
+
+func (s *MySuite) TestOnEOF(c *C) {
+	mrseeker, err := NewFromStrings("ABC", "DEF")
+	c.Assert(err, IsNil)
+	eofs := 0
+	mrseeker.OnEOF(func() { eofs++ })
+
+	buf := make([]byte, 5)
+	_, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(eofs, Equals, 0)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(n, Equals, 1)
+	c.Check(eofs, Equals, 1)
+	_, err = mrseeker.Read(buf)
+	c.Check(err, Equals, io.EOF)
+	c.Check(eofs, Equals, 1)
+
+	_, err = mrseeker.Seek(-2, io.SeekEnd)
+	c.Assert(err, IsNil)
+	_, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(eofs, Equals, 2)
+}
+
+func (s *MySuite) TestOnError(c *C) {
+	errBoom := errors.New("boom")
+	child2 := newScriptedChild("DEF")
+	child2.failAt = 1
+	child2.failErr = errBoom
+	child3 := newScriptedChild("GHI")
+	child3.closeErr = errBoom
+	mrseeker, err := New(newScriptedChild("ABC"), child2, child3)
+	c.Assert(err, IsNil)
+	var indices []int
+	var errs []error
+	mrseeker.OnError(func(childIdx int, err error) {
+		indices = append(indices, childIdx)
+		errs = append(errs, err)
+	})
+
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Check(errors.Is(err, errBoom), Equals, true)
+	c.Check(string(data), Equals, "ABCD")
+	c.Check(indices, DeepEquals, []int{1})
+	c.Check(errs[0], Equals, err)
+
+	child2.failAt = -1
+	child2.seekErr = errBoom
+	_, err = mrseeker.Seek(4, io.SeekStart)
+	c.Check(errors.Is(err, errBoom), Equals, true)
+	c.Check(indices, DeepEquals, []int{1, 1})
+
+	// The error isn't changed, and it is still returned
+	err = mrseeker.Close()
+	c.Check(errors.Is(errors.Cause(err.(errset.ErrSet)[0]), errBoom), Equals, true)
+	c.Check(indices, DeepEquals, []int{1, 1, 2})
+}
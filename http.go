@@ -0,0 +1,25 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Serving a MultiReadSeeker over HTTP.
+
+import (
+	"net/http"
+)
+
+// ServeHTTP serves the MultiReadSeeker as a file, with
+// http.ServeContent, so that Range requests get byte ranges of it. The
+// name and modification time are from Stat. Each request reads with
+// its own Reader from NewReader, so the MultiReadSeeker's position
+// doesn't change; as with NewReader, requests can only be served at
+// the same time if the children allow it.
+func (self *MultiReadSeeker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	info, err := self.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	reader := self.NewReader()
+	defer reader.Close()
+	http.ServeContent(w, r, info.Name(), info.ModTime(), reader)
+}
@@ -0,0 +1,43 @@
+package multireadseeker
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestServeHTTP(c *C) {
+	var data []byte
+	for i := 0; i < 300; i++ {
+		data = append(data, byte(i))
+	}
+	mrseeker, err := NewFromBytes(data[:120], data[120:180], data[180:])
+	c.Assert(err, IsNil)
+	server := httptest.NewServer(mrseeker)
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
+	c.Assert(err, IsNil)
+	request.Header.Set("Range", "bytes=100-199")
+	response, err := http.DefaultClient.Do(request)
+	c.Assert(err, IsNil)
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	c.Assert(err, IsNil)
+	c.Check(response.StatusCode, Equals, http.StatusPartialContent)
+	c.Check(response.Header.Get("Content-Range"), Equals, "bytes 100-199/300")
+	c.Check(bytes.Equal(body, data[100:200]), Equals, true)
+
+	// The whole file; the position of the MultiReadSeeker doesn't move
+	response, err = http.Get(server.URL)
+	c.Assert(err, IsNil)
+	body, err = ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	c.Assert(err, IsNil)
+	c.Check(response.StatusCode, Equals, http.StatusOK)
+	c.Check(bytes.Equal(body, data), Equals, true)
+	c.Check(mrseeker.Tell(), Equals, int64(0))
+}
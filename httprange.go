@@ -0,0 +1,142 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// A child that reads byte ranges of a URL.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+type httpRangeChild struct {
+	url    string
+	client *http.Client
+	size   int64
+	pos    int64
+
+	// Close cancels the requests
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// HTTPRangeChild returns a child that reads url with HTTP Range
+// requests, as for a large object in object storage, of which only a
+// part is read. The size comes from the Content-Length of a HEAD
+// request. Each Read, and each ReadAt, is a GET of the bytes it reads,
+// so a buffer, as from WithBufferSize, saves many small requests. Seek
+// doesn't make a request. Close cancels a request that is in progress.
+// If client is nil, http.DefaultClient is used.
+func HTTPRangeChild(url string, client *http.Client) (ReadCloseSeeker, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	child := &httpRangeChild{
+		url:    url,
+		client: client,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		cancel()
+		return nil, errors.Errorf("HEAD %s: %s", url, response.Status)
+	}
+	if response.ContentLength < 0 {
+		cancel()
+		return nil, errors.Errorf("HEAD %s: no Content-Length", url)
+	}
+	child.size = response.ContentLength
+	return child, nil
+}
+
+func (self *httpRangeChild) Read(p []byte) (int, error) {
+	n, err := self.ReadAt(p, self.pos)
+	self.pos += int64(n)
+	if err == io.EOF && n > 0 {
+		// The next Read returns io.EOF
+		err = nil
+	}
+	return n, err
+}
+
+func (self *httpRangeChild) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.Wrapf(ErrNegativeSeek, "ReadAt offset %d", off)
+	}
+	if off >= self.size {
+		return 0, io.EOF
+	}
+	short := int64(len(p)) > self.size-off
+	if short {
+		p = p[:self.size-off]
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	request, err := http.NewRequestWithContext(self.ctx, http.MethodGet, self.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1))
+	response, err := self.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusPartialContent {
+		return 0, errors.Errorf("GET %s, bytes %d-%d: %s", self.url,
+			off, off+int64(len(p))-1, response.Status)
+	}
+
+	n, err := io.ReadFull(response.Body, p)
+	if err == io.EOF {
+		// The object is shorter than when HEAD found its size
+		err = io.ErrUnexpectedEOF
+	}
+	if err == nil && short {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (self *httpRangeChild) Seek(offset int64, whence int) (int64, error) {
+	var newPos int64
+	switch whence {
+	case io.SeekStart:
+		newPos = offset
+	case io.SeekCurrent:
+		newPos = self.pos + offset
+	case io.SeekEnd:
+		newPos = self.size + offset
+	default:
+		return self.pos, errors.Errorf(
+			"Seek(offset, whence); whence must be 0, 1, or 2, not %d", whence)
+	}
+	if newPos < 0 {
+		return self.pos, errors.Wrapf(ErrNegativeSeek, "Seek to %d", newPos)
+	}
+	self.pos = newPos
+	return self.pos, nil
+}
+
+func (self *httpRangeChild) Close() error {
+	self.cancel()
+	return nil
+}
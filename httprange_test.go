@@ -0,0 +1,73 @@
+package multireadseeker
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestHTTPRangeChild(c *C) {
+	object, err := NewFromStrings("ABCDEFGHIJ", "KLMNOPQRST")
+	c.Assert(err, IsNil)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			ranges = append(ranges, r.Header.Get("Range"))
+		}
+		object.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	child, err := HTTPRangeChild(server.URL, nil)
+	c.Assert(err, IsNil)
+	size, err := child.Seek(0, io.SeekEnd)
+	c.Assert(err, IsNil)
+	c.Check(size, Equals, int64(20))
+	_, err = child.Seek(8, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Check(ranges, HasLen, 0)
+
+	buf := make([]byte, 4)
+	n, err := child.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "IJKL")
+	c.Check(ranges, DeepEquals, []string{"bytes=8-11"})
+
+	// The last Read is short
+	_, err = child.Seek(-2, io.SeekEnd)
+	c.Assert(err, IsNil)
+	n, err = child.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "ST")
+	_, err = child.Read(buf)
+	c.Check(err, Equals, io.EOF)
+	n, err = child.(io.ReaderAt).ReadAt(buf, 17)
+	c.Check(err, Equals, io.EOF)
+	c.Check(string(buf[:n]), Equals, "RST")
+
+	// As a child
+	_, err = child.Seek(0, io.SeekStart)
+	c.Assert(err, IsNil)
+	mrseeker, err := New(child, StringChild("UV"))
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFGHIJKLMNOPQRSTUV")
+
+	// Requests fail after Close
+	c.Assert(mrseeker.Close(), IsNil)
+	_, err = child.(io.ReaderAt).ReadAt(buf, 0)
+	c.Check(errors.Is(err, context.Canceled), Equals, true)
+}
+
+func (s *MySuite) TestHTTPRangeChildErrors(c *C) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	_, err := HTTPRangeChild(server.URL, server.Client())
+	c.Check(err, ErrorMatches, "HEAD .*: 404 Not Found")
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+
+//go:build go1.23
+
+package multireadseeker
+
+// Iterators, for range-over-func.
+
+import (
+	"io"
+	"iter"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Lines returns an iterator over the lines from the current position
+// to the end, without their "\n" or "\r\n", for
+// "for line, err := range mrseeker.Lines()". A line can be in more
+// than one child. If reading fails, the error is yielded, with what
+// was read of the line, and the iteration ends.
+func (self *MultiReadSeeker) Lines() iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for {
+			line, found, err := self.readUntil('\n', 0)
+			if found {
+				line = line[:len(line)-1]
+				if len(line) > 0 && line[len(line)-1] == '\r' {
+					line = line[:len(line)-1]
+				}
+			}
+			if err != nil && err != io.EOF {
+				yield(string(line), err)
+				return
+			}
+			if len(line) > 0 || found {
+				if !yield(string(line), nil) {
+					return
+				}
+			}
+			if err == io.EOF {
+				return
+			}
+		}
+	}
+}
+
+// Chunks returns an iterator over the bytes from the current position
+// to the end, in chunks of size bytes; the last chunk may be shorter.
+// Each chunk is a new slice. If reading fails, the error is yielded,
+// with what was read of the chunk, and the iteration ends.
+func (self *MultiReadSeeker) Chunks(size int) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		if size <= 0 {
+			yield(nil, errors.Errorf("Chunks(%d); size must be > 0", size))
+			return
+		}
+		for {
+			chunk := make([]byte, size)
+			if !self.yieldChunk(chunk, yield) {
+				return
+			}
+		}
+	}
+}
+
+// ChunksPooled is like Chunks, but every chunk is in the same slice,
+// which comes from a pool, and goes back to it when the iteration ends.
+// So a chunk can only be used until the loop goes on to the next
+// one.
+func (self *MultiReadSeeker) ChunksPooled(size int) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		if size <= 0 {
+			yield(nil, errors.Errorf("ChunksPooled(%d); size must be > 0", size))
+			return
+		}
+		pool := chunkPool(size)
+		bufp := pool.Get().(*[]byte)
+		defer pool.Put(bufp)
+		for self.yieldChunk(*bufp, yield) {
+		}
+	}
+}
+
+// Read the next chunk into chunk and yield it. Returns whether to go on.
+func (self *MultiReadSeeker) yieldChunk(chunk []byte, yield func([]byte, error) bool) bool {
+	n, err := self.ReadFull(chunk)
+	if err == io.EOF {
+		return false
+	}
+	if err == io.ErrUnexpectedEOF {
+		// The last chunk
+		yield(chunk[:n], nil)
+		return false
+	}
+	if err != nil {
+		yield(chunk[:n], err)
+		return false
+	}
+	return yield(chunk, nil)
+}
+
+// The pools for ChunksPooled, by chunk size
+var chunkPools sync.Map
+
+func chunkPool(size int) *sync.Pool {
+	pool, ok := chunkPools.Load(size)
+	if !ok {
+		pool, _ = chunkPools.LoadOrStore(size, &sync.Pool{
+			New: func() interface{} {
+				buf := make([]byte, size)
+				return &buf
+			},
+		})
+	}
+	return pool.(*sync.Pool)
+}
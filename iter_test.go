@@ -0,0 +1,70 @@
+//go:build go1.23
+
+package multireadseeker
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestLines(c *C) {
+	mrseeker, err := NewFromStrings("one\r\ntw", "o\n\nthr", "ee")
+	c.Assert(err, IsNil)
+	var lines []string
+	for line, err := range mrseeker.Lines() {
+		c.Assert(err, IsNil)
+		lines = append(lines, line)
+	}
+	c.Check(lines, DeepEquals, []string{"one", "two", "", "three"})
+
+	// Stopping early
+	mrseeker, err = NewFromStrings("one\ntwo\n")
+	c.Assert(err, IsNil)
+	for line := range mrseeker.Lines() {
+		c.Check(line, Equals, "one")
+		break
+	}
+	c.Check(mrseeker.Tell(), Equals, int64(4))
+
+	errBoom := errors.New("boom")
+	bad := newScriptedChild("XYZ")
+	bad.failAt = 1
+	bad.failErr = errBoom
+	mrseeker, err = New(newScriptedChild("a\nb"), bad)
+	c.Assert(err, IsNil)
+	lines = nil
+	var errs []error
+	for line, err := range mrseeker.Lines() {
+		lines = append(lines, line)
+		errs = append(errs, err)
+	}
+	c.Check(lines, DeepEquals, []string{"a", "bX"})
+	c.Check(errs[0], IsNil)
+	c.Check(errors.Is(errs[1], errBoom), Equals, true)
+}
+
+func (s *MySuite) TestChunks(c *C) {
+	mrseeker, err := NewFromStrings("ABCD", "EFG", "HIJ")
+	c.Assert(err, IsNil)
+	var chunks []string
+	for chunk, err := range mrseeker.Chunks(4) {
+		c.Assert(err, IsNil)
+		chunks = append(chunks, string(chunk))
+	}
+	c.Check(chunks, DeepEquals, []string{"ABCD", "EFGH", "IJ"})
+
+	_, err = mrseeker.Seek(2, io.SeekStart)
+	c.Assert(err, IsNil)
+	chunks = nil
+	for chunk, err := range mrseeker.ChunksPooled(4) {
+		c.Assert(err, IsNil)
+		chunks = append(chunks, string(chunk))
+	}
+	c.Check(chunks, DeepEquals, []string{"CDEF", "GHIJ"})
+
+	for _, err := range mrseeker.Chunks(0) {
+		c.Check(err, ErrorMatches, "Chunks\\(0\\); size must be > 0")
+	}
+}
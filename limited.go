@@ -0,0 +1,186 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Reading only the first bytes of a child.
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// A child that is only the first n bytes of another child
+type limitedChild struct {
+	child ReadCloseSeeker
+	n     int64
+	pos   int64
+}
+
+// A limitedChild for a child that has ReadAt, so that it has ReadAt too
+type limitedReaderAtChild struct {
+	*limitedChild
+	readerAt io.ReaderAt
+}
+
+// LimitedReadCloseSeeker returns a child that is only the first n
+// bytes of r, like io.LimitedReader, but which can seek. Seeks to or
+// past n go to n. If r is shorter than n, the child is as long as r.
+// Close closes r.
+func LimitedReadCloseSeeker(r ReadCloseSeeker, n int64) ReadCloseSeeker {
+	if n < 0 {
+		n = 0
+	}
+	limited := &limitedChild{
+		child: r,
+		n:     n,
+	}
+	if readerAt, ok := r.(io.ReaderAt); ok {
+		return limitedReaderAtChild{limited, readerAt}
+	}
+	return limited
+}
+
+func (self *limitedChild) Read(p []byte) (int, error) {
+	remaining := self.n - self.pos
+	if remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := self.child.Read(p)
+	self.pos += int64(n)
+	return n, err
+}
+
+func (self *limitedChild) Seek(offset int64, whence int) (int64, error) {
+	var newPos int64
+	switch whence {
+	case io.SeekStart:
+		newPos = offset
+	case io.SeekCurrent:
+		newPos = self.pos + offset
+	case io.SeekEnd:
+		// The end is n, or the end of the child if it is shorter
+		end, err := self.child.Seek(0, io.SeekEnd)
+		if err != nil {
+			return self.pos, err
+		}
+		if end > self.n {
+			end = self.n
+		}
+		newPos = end + offset
+	default:
+		return self.pos, errors.Errorf(
+			"Seek(offset, whence); whence must be 0, 1, or 2, not %d", whence)
+	}
+	if newPos < 0 {
+		return self.pos, errors.Wrapf(ErrNegativeSeek, "Seek to %d", newPos)
+	}
+	if newPos > self.n {
+		newPos = self.n
+	}
+	pos, err := self.child.Seek(newPos, io.SeekStart)
+	if err != nil {
+		return self.pos, err
+	}
+	self.pos = pos
+	return self.pos, nil
+}
+
+func (self *limitedChild) Close() error {
+	return self.child.Close()
+}
+
+func (self limitedReaderAtChild) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.Wrapf(ErrNegativeSeek, "ReadAt offset %d", off)
+	}
+	remaining := self.n - off
+	if remaining <= 0 {
+		return 0, io.EOF
+	}
+	short := int64(len(p)) > remaining
+	if short {
+		p = p[:remaining]
+	}
+	n, err := self.readerAt.ReadAt(p, off)
+	if err == nil && short {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// A view of part of a MultiReadSeeker, from Limit
+type limitedView struct {
+	parent *MultiReadSeeker
+	start  int64
+	size   int64
+	pos    int64
+}
+
+// Limit returns an io.ReadSeeker that reads at most n bytes from the
+// current position, like io.LimitReader, as to hand a record of known
+// length to a parser. Its positions are relative to the current
+// position, and Seeks past n go to n. It also has a Size method, which
+// returns the smaller of n and Remaining(). As with NewReader, it reads
+// with ReadAt, so the MultiReadSeeker's position doesn't change.
+func (self *MultiReadSeeker) Limit(n int64) io.ReadSeeker {
+	size := self.Remaining()
+	if n < size {
+		size = n
+	}
+	if size < 0 {
+		size = 0
+	}
+	return &limitedView{
+		parent: self,
+		start:  self.currentSuperPos,
+		size:   size,
+	}
+}
+
+// Size returns the number of bytes that can be read, from the start.
+func (self *limitedView) Size() int64 {
+	return self.size
+}
+
+func (self *limitedView) Read(p []byte) (int, error) {
+	remaining := self.size - self.pos
+	if remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := self.parent.ReadAt(p, self.start+self.pos)
+	self.pos += int64(n)
+	if err == io.EOF && n > 0 {
+		// The next Read returns io.EOF
+		err = nil
+	}
+	return n, err
+}
+
+func (self *limitedView) Seek(offset int64, whence int) (int64, error) {
+	var newPos int64
+	switch whence {
+	case io.SeekStart:
+		newPos = offset
+	case io.SeekCurrent:
+		newPos = self.pos + offset
+	case io.SeekEnd:
+		newPos = self.size + offset
+	default:
+		return self.pos, errors.Errorf(
+			"Seek(offset, whence); whence must be 0, 1, or 2, not %d", whence)
+	}
+	if newPos < 0 {
+		return self.pos, errors.Wrapf(ErrNegativeSeek, "Seek to %d", newPos)
+	}
+	if newPos > self.size {
+		newPos = self.size
+	}
+	self.pos = newPos
+	return self.pos, nil
+}
@@ -0,0 +1,98 @@
+package multireadseeker
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestLimitedReadCloseSeeker(c *C) {
+	child := LimitedReadCloseSeeker(newSeekOnlyChild("ABCDEF"), 4)
+	_, ok := child.(io.ReaderAt)
+	c.Check(ok, Equals, false)
+	data, err := ioutil.ReadAll(child)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCD")
+
+	pos, err := child.Seek(2, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(2))
+	data, err = ioutil.ReadAll(child)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "CD")
+
+	// Seeks past n go to n
+	pos, err = child.Seek(10, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(4))
+	pos, err = child.Seek(0, io.SeekEnd)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(4))
+	buf := make([]byte, 2)
+	n, err := child.Read(buf)
+	c.Check(n, Equals, 0)
+	c.Check(err, Equals, io.EOF)
+
+	// A child shorter than n
+	short := LimitedReadCloseSeeker(StringChild("XY"), 4)
+	pos, err = short.Seek(0, io.SeekEnd)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(2))
+	_, err = short.Seek(0, io.SeekStart)
+	c.Assert(err, IsNil)
+
+	mrseeker, err := New(child, short,
+		LimitedReadCloseSeeker(StringChild("GHIJ"), 1))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Size(), Equals, int64(7))
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDXYG")
+
+	buf = make([]byte, 4)
+	n, err = mrseeker.ReadAt(buf, 3)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "DXYG")
+	c.Assert(mrseeker.Close(), IsNil)
+}
+
+func (s *MySuite) TestLimit(c *C) {
+	mrseeker, err := NewFromStrings("ABC", "DEF", "GHI")
+	c.Assert(err, IsNil)
+	_, err = mrseeker.Seek(2, io.SeekStart)
+	c.Assert(err, IsNil)
+
+	limited := mrseeker.Limit(4)
+	c.Check(limited.(interface{ Size() int64 }).Size(), Equals, int64(4))
+	data, err := ioutil.ReadAll(limited)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "CDEF")
+	c.Check(mrseeker.Tell(), Equals, int64(2))
+
+	// Seeks are clamped to the limit
+	pos, err := limited.Seek(10, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(4))
+	pos, err = limited.Seek(-3, io.SeekEnd)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(1))
+	buf := make([]byte, 2)
+	_, err = io.ReadFull(limited, buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf), Equals, "DE")
+	_, err = limited.Seek(-5, io.SeekCurrent)
+	c.Check(errors.Is(err, ErrNegativeSeek), Equals, true)
+
+	// No more than what remains
+	limited = mrseeker.Limit(100)
+	c.Check(limited.(interface{ Size() int64 }).Size(), Equals, int64(7))
+	data, err = ioutil.ReadAll(limited)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "CDEFGHI")
+
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "CDEFGHI")
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Reading up to a delimiter, like bufio.Reader, but without reading
+// ahead, so that the position is still right for Seek.
+
+import (
+	"bytes"
+	"io"
+)
+
+// The longest line that ReadLine returns whole, as with the default
+// size of a bufio.Reader
+const maxLineLength = 4096
+
+// How much Read reads at a time when looking for a delimiter
+const delimChunkSize = 512
+
+// ReadLine reads a line, as bufio.Reader.ReadLine does: the line
+// doesn't include the "\n" or "\r\n" at its end. A line longer than
+// 4096 bytes is returned in parts, with isPrefix set on all but the
+// last. It returns either a line or an error, never both; the line is
+// a new slice each time.
+func (self *MultiReadSeeker) ReadLine() (line []byte, isPrefix bool, err error) {
+	line, found, err := self.readUntil('\n', maxLineLength)
+	if len(line) == 0 {
+		return nil, false, err
+	}
+	if found {
+		line = line[:len(line)-1]
+		if len(line) > 0 && line[len(line)-1] == '\r' {
+			line = line[:len(line)-1]
+		}
+		return line, false, nil
+	}
+	if err == nil {
+		// Too long
+		if line[len(line)-1] == '\r' && len(line) > 1 {
+			// Leave it for the next call, in case "\n" is next
+			_, err = self.Seek(-1, io.SeekCurrent)
+			if err != nil {
+				return nil, false, err
+			}
+			line = line[:len(line)-1]
+		}
+		return line, true, nil
+	}
+	// The last line, without a "\n"; the next call returns io.EOF
+	if err == io.EOF {
+		return line, false, nil
+	}
+	return nil, false, err
+}
+
+// ReadBytes reads up to and including the first delim, as
+// bufio.Reader.ReadBytes does, from as many children as it takes. If
+// delim isn't found, it returns the bytes up to the end, and io.EOF.
+func (self *MultiReadSeeker) ReadBytes(delim byte) ([]byte, error) {
+	data, _, err := self.readUntil(delim, 0)
+	return data, err
+}
+
+// ReadString is ReadBytes, returning a string.
+func (self *MultiReadSeeker) ReadString(delim byte) (string, error) {
+	data, err := self.ReadBytes(delim)
+	return string(data), err
+}
+
+// Read up to and including delim, or limit bytes if limit > 0, and
+// report whether delim was found. If it wasn't, the error is io.EOF at
+// the end, or nil if the limit was reached.
+func (self *MultiReadSeeker) readUntil(delim byte, limit int) ([]byte, bool, error) {
+	var data []byte
+	for limit <= 0 || len(data) < limit {
+		// Don't read past the end of the child; if delim is found,
+		// the bytes after it are put back with Seek, and that child
+		// may be closed once Read moves on from it
+		n := int64(delimChunkSize)
+		if limit > 0 && int64(limit-len(data)) < n {
+			n = int64(limit - len(data))
+		}
+		if i := self.findSeekIndex(self.currentSuperPos); i != seekImpossible {
+			if childRemaining := self.superPosEnd[i] - self.currentSuperPos; childRemaining < n {
+				n = childRemaining
+			}
+		}
+
+		start := len(data)
+		data = append(data, make([]byte, n)...)
+		numRead, err := self.Read(data[start:])
+		data = data[:start+numRead]
+		if i := bytes.IndexByte(data[start:], delim); i >= 0 {
+			extra := numRead - i - 1
+			data = data[:start+i+1]
+			if extra > 0 {
+				_, err = self.Seek(-int64(extra), io.SeekCurrent)
+				if err != nil {
+					return nil, false, err
+				}
+			}
+			return data, true, nil
+		}
+		if err != nil {
+			return data, false, err
+		}
+	}
+	return data, false, nil
+}
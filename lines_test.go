@@ -0,0 +1,95 @@
+package multireadseeker
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestReadLine(c *C) {
+	mrseeker, err := NewFromStrings("one\r\ntw", "o\nthr", "ee")
+	c.Assert(err, IsNil)
+	var lines []string
+	for {
+		line, isPrefix, err := mrseeker.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, IsNil)
+		c.Check(isPrefix, Equals, false)
+		lines = append(lines, string(line))
+	}
+	c.Check(lines, DeepEquals, []string{"one", "two", "three"})
+
+	// The position is right after the line, so it can be seeked
+	_, err = mrseeker.Seek(0, io.SeekStart)
+	c.Assert(err, IsNil)
+	line, _, err := mrseeker.ReadLine()
+	c.Assert(err, IsNil)
+	c.Check(string(line), Equals, "one")
+	c.Check(mrseeker.Tell(), Equals, int64(5))
+	rest, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(rest), Equals, "two\nthree")
+}
+
+func (s *MySuite) TestReadLineLong(c *C) {
+	long := strings.Repeat("x", maxLineLength-1) + "\r"
+	mrseeker, err := NewFromStrings(long, "\nend\n")
+	c.Assert(err, IsNil)
+	line, isPrefix, err := mrseeker.ReadLine()
+	c.Assert(err, IsNil)
+	c.Check(isPrefix, Equals, true)
+	c.Check(string(line), Equals, long[:len(long)-1])
+	// The "\r" was left for the next call
+	line, isPrefix, err = mrseeker.ReadLine()
+	c.Assert(err, IsNil)
+	c.Check(isPrefix, Equals, false)
+	c.Check(string(line), Equals, "")
+	line, _, err = mrseeker.ReadLine()
+	c.Assert(err, IsNil)
+	c.Check(string(line), Equals, "end")
+	_, _, err = mrseeker.ReadLine()
+	c.Check(err, Equals, io.EOF)
+}
+
+func (s *MySuite) TestReadLineCloseOnEOF(c *C) {
+	// Putting back the bytes after the line doesn't need a child that
+	// has been closed
+	mrseeker, err := NewWithOptions([]Option{WithCloseOnEOF(true)},
+		StringChild("a"), StringChild("b\nc"))
+	c.Assert(err, IsNil)
+	line, _, err := mrseeker.ReadLine()
+	c.Assert(err, IsNil)
+	c.Check(string(line), Equals, "ab")
+	line, _, err = mrseeker.ReadLine()
+	c.Assert(err, IsNil)
+	c.Check(string(line), Equals, "c")
+}
+
+func (s *MySuite) TestReadBytes(c *C) {
+	mrseeker, err := NewFromStrings("a,b", "c", "d,e")
+	c.Assert(err, IsNil)
+	data, err := mrseeker.ReadBytes(',')
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "a,")
+	// Across three children
+	data, err = mrseeker.ReadBytes(',')
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "bcd,")
+	data, err = mrseeker.ReadBytes(',')
+	c.Check(err, Equals, io.EOF)
+	c.Check(string(data), Equals, "e")
+	data, err = mrseeker.ReadBytes(',')
+	c.Check(err, Equals, io.EOF)
+	c.Check(data, HasLen, 0)
+
+	_, err = mrseeker.Seek(2, io.SeekStart)
+	c.Assert(err, IsNil)
+	str, err := mrseeker.ReadString(',')
+	c.Assert(err, IsNil)
+	c.Check(str, Equals, "bcd,")
+	c.Check(mrseeker.Tell(), Equals, int64(6))
+}
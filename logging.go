@@ -0,0 +1,86 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Logging the I/O on a child.
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// LoggingReadCloseSeeker wraps a child and logs each Read, Seek, and
+// Close, with its arguments, results, and duration. Like
+// CountingReadCloseSeeker, it doesn't pass on io.ReaderAt.
+type LoggingReadCloseSeeker struct {
+	child  ReadCloseSeeker
+	logger *slog.Logger
+	level  slog.Level
+	name   string
+}
+
+// NewLoggingReadCloseSeeker wraps child in a LoggingReadCloseSeeker
+// that logs to logger at slog.LevelDebug; name identifies the child in
+// each entry. If logger is nil, slog.Default() is used.
+func NewLoggingReadCloseSeeker(child ReadCloseSeeker, logger *slog.Logger,
+	name string) *LoggingReadCloseSeeker {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &LoggingReadCloseSeeker{
+		child:  child,
+		logger: logger,
+		level:  slog.LevelDebug,
+		name:   name,
+	}
+}
+
+// SetLevel sets the level that entries are logged at.
+func (self *LoggingReadCloseSeeker) SetLevel(level slog.Level) {
+	self.level = level
+}
+
+func (self *LoggingReadCloseSeeker) enabled() bool {
+	return self.logger.Enabled(context.Background(), self.level)
+}
+
+func (self *LoggingReadCloseSeeker) log(op string, start time.Time,
+	err error, attrs ...slog.Attr) {
+	attrs = append([]slog.Attr{slog.String("child", self.name)}, attrs...)
+	if err != nil {
+		attrs = append(attrs, slog.String("err", err.Error()))
+	}
+	attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+	self.logger.LogAttrs(context.Background(), self.level, op, attrs...)
+}
+
+func (self *LoggingReadCloseSeeker) Read(p []byte) (int, error) {
+	if !self.enabled() {
+		return self.child.Read(p)
+	}
+	start := time.Now()
+	n, err := self.child.Read(p)
+	self.log("Read", start, err, slog.Int("len", len(p)), slog.Int("n", n))
+	return n, err
+}
+
+func (self *LoggingReadCloseSeeker) Seek(offset int64, whence int) (int64, error) {
+	if !self.enabled() {
+		return self.child.Seek(offset, whence)
+	}
+	start := time.Now()
+	pos, err := self.child.Seek(offset, whence)
+	self.log("Seek", start, err, slog.Int64("offset", offset),
+		slog.Int("whence", whence), slog.Int64("pos", pos))
+	return pos, err
+}
+
+func (self *LoggingReadCloseSeeker) Close() error {
+	if !self.enabled() {
+		return self.child.Close()
+	}
+	start := time.Now()
+	err := self.child.Close()
+	self.log("Close", start, err)
+	return err
+}
@@ -0,0 +1,50 @@
+package multireadseeker
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestLoggingReadCloseSeeker(c *C) {
+	var out bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&out,
+		&slog.HandlerOptions{Level: slog.LevelDebug}))
+	child := NewLoggingReadCloseSeeker(StringChild("ABC"), logger, "first")
+
+	_, err := child.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	buf := make([]byte, 4)
+	_, err = child.Read(buf)
+	c.Assert(err, IsNil)
+	_, err = child.Read(buf)
+	c.Assert(err, Equals, io.EOF)
+	err = child.Close()
+	c.Assert(err, IsNil)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	c.Assert(lines, HasLen, 4)
+	c.Check(lines[0], Matches,
+		`.*level=DEBUG msg=Seek child=first offset=1 whence=0 pos=1 duration=.*`)
+	c.Check(lines[1], Matches,
+		`.*level=DEBUG msg=Read child=first len=4 n=2 duration=.*`)
+	c.Check(lines[2], Matches,
+		`.*level=DEBUG msg=Read child=first len=4 n=0 err=EOF duration=.*`)
+	c.Check(lines[3], Matches, `.*level=DEBUG msg=Close child=first duration=.*`)
+
+	// Below the logger's level, nothing is logged
+	out.Reset()
+	logger = slog.New(slog.NewTextHandler(&out, nil))
+	child = NewLoggingReadCloseSeeker(StringChild("ABC"), logger, "second")
+	_, err = child.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(out.Len(), Equals, 0)
+
+	child.SetLevel(slog.LevelInfo)
+	_, err = child.Seek(0, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Check(out.String(), Matches, `.*level=INFO msg=Seek child=second .*\n`)
+}
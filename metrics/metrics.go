@@ -0,0 +1,91 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+
+// Package metrics exports Prometheus metrics of what MultiReadSeekers
+// do. It is separate so that only programs that use it depend on
+// github.com/prometheus/client_golang.
+package metrics
+
+import (
+	"time"
+
+	multireadseeker "github.com/gilramir/concatfile"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type observer struct {
+	bytesRead     prometheus.Counter
+	readCalls     prometheus.Counter
+	seekCalls     prometheus.Counter
+	childSwitches prometheus.Counter
+	readDuration  prometheus.Histogram
+}
+
+// WithPrometheusMetrics registers these metrics with reg, and returns
+// an Option that makes a MultiReadSeeker count what it does in them:
+//
+//	multireadseeker_bytes_read_total
+//	multireadseeker_read_calls_total
+//	multireadseeker_seek_calls_total
+//	multireadseeker_child_switches_total
+//	multireadseeker_read_duration_seconds, a histogram
+//
+// All the MultiReadSeekers given an Option for the same reg are counted
+// together; if the metrics are already registered with reg, they are
+// used again. As with prometheus.MustRegister, it panics if they can't
+// be registered.
+func WithPrometheusMetrics(reg prometheus.Registerer) multireadseeker.Option {
+	self := &observer{
+		bytesRead: register(reg, prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "multireadseeker_bytes_read_total",
+			Help: "Bytes returned by Read.",
+		})).(prometheus.Counter),
+		readCalls: register(reg, prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "multireadseeker_read_calls_total",
+			Help: "Calls to Read.",
+		})).(prometheus.Counter),
+		seekCalls: register(reg, prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "multireadseeker_seek_calls_total",
+			Help: "Calls to Seek.",
+		})).(prometheus.Counter),
+		childSwitches: register(reg, prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "multireadseeker_child_switches_total",
+			Help: "Times that Read moved from one child to the next.",
+		})).(prometheus.Counter),
+		readDuration: register(reg, prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "multireadseeker_read_duration_seconds",
+			Help:    "How long each call to Read took.",
+			Buckets: prometheus.DefBuckets,
+		})).(prometheus.Histogram),
+	}
+	return multireadseeker.WithObserver(self)
+}
+
+// Register collector with reg, or return the one that is already
+// registered
+func register(reg prometheus.Registerer, collector prometheus.Collector) prometheus.Collector {
+	err := reg.Register(collector)
+	if err == nil {
+		return collector
+	}
+	if already, ok := err.(prometheus.AlreadyRegisteredError); ok {
+		return already.ExistingCollector
+	}
+	panic(err)
+}
+
+func (self *observer) StartRead(mrseeker *multireadseeker.MultiReadSeeker, requested int) func(n int, err error) {
+	start := time.Now()
+	return func(n int, err error) {
+		self.readCalls.Inc()
+		self.bytesRead.Add(float64(n))
+		self.readDuration.Observe(time.Since(start).Seconds())
+	}
+}
+
+func (self *observer) Seeked(mrseeker *multireadseeker.MultiReadSeeker, pos int64, err error) {
+	self.seekCalls.Inc()
+}
+
+func (self *observer) ChildSwitched(mrseeker *multireadseeker.MultiReadSeeker, fromIndex, toIndex int) {
+	self.childSwitches.Inc()
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"io"
+	"io/ioutil"
+	"testing"
+
+	multireadseeker "github.com/gilramir/concatfile"
+	"github.com/prometheus/client_golang/prometheus"
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+func (s *MySuite) TestPrometheusMetrics(c *C) {
+	reg := prometheus.NewRegistry()
+	mrseeker, err := multireadseeker.NewWithOptions(
+		[]multireadseeker.Option{WithPrometheusMetrics(reg)},
+		multireadseeker.StringChild("ABC"), multireadseeker.StringChild("DEFG"))
+	c.Assert(err, IsNil)
+
+	_, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	_, err = mrseeker.Seek(2, io.SeekStart)
+	c.Assert(err, IsNil)
+
+	// A second MultiReadSeeker is counted in the same metrics
+	other, err := multireadseeker.NewWithOptions(
+		[]multireadseeker.Option{WithPrometheusMetrics(reg)},
+		multireadseeker.StringChild("HI"))
+	c.Assert(err, IsNil)
+	buf := make([]byte, 2)
+	_, err = other.Read(buf)
+	c.Assert(err, IsNil)
+
+	c.Check(gathered(c, reg, "multireadseeker_bytes_read_total"), Equals, float64(9))
+	c.Check(gathered(c, reg, "multireadseeker_seek_calls_total"), Equals, float64(1))
+	c.Check(gathered(c, reg, "multireadseeker_child_switches_total"), Equals, float64(1))
+	readCalls := gathered(c, reg, "multireadseeker_read_calls_total")
+	c.Check(readCalls > 2, Equals, true)
+	c.Check(gathered(c, reg, "multireadseeker_read_duration_seconds"), Equals, readCalls)
+}
+
+// The value of a counter, or the number of samples of a histogram,
+// from reg
+func gathered(c *C, reg prometheus.Gatherer, name string) float64 {
+	families, err := reg.Gather()
+	c.Assert(err, IsNil)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		metric := family.GetMetric()[0]
+		if histogram := metric.GetHistogram(); histogram != nil {
+			return float64(histogram.GetSampleCount())
+		}
+		return metric.GetCounter().GetValue()
+	}
+	c.Fatalf("No metric %s", name)
+	return 0
+}
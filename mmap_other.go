@@ -0,0 +1,17 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris)
+
+package multireadseeker
+
+import (
+	"runtime"
+
+	"github.com/pkg/errors"
+)
+
+// MmapChild maps files only on Unix; see mmap_unix.go.
+func MmapChild(path string) (ReadCloseSeeker, error) {
+	return nil, errors.Errorf("MmapChild of %s isn't supported on %s",
+		path, runtime.GOOS)
+}
@@ -0,0 +1,55 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package multireadseeker
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestMmapChild(c *C) {
+	names := s.writeDataFiles(c, "mmap", strings.Repeat("0123456789", 1000), "")
+
+	child, err := MmapChild(names[0])
+	c.Assert(err, IsNil)
+	expected, err := os.ReadFile(names[0])
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(child)
+	c.Assert(err, IsNil)
+	c.Check(data, DeepEquals, expected)
+
+	pos, err := child.Seek(-5, io.SeekEnd)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(9995))
+	buf := make([]byte, 10)
+	n, err := child.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "56789")
+	n, err = child.(io.ReaderAt).ReadAt(buf, 3)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "3456789012")
+
+	// As a child, alongside an empty file
+	empty, err := MmapChild(names[1])
+	c.Assert(err, IsNil)
+	_, err = child.Seek(0, io.SeekStart)
+	c.Assert(err, IsNil)
+	mrseeker, err := New(child, empty)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Size(), Equals, int64(10000))
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(data, DeepEquals, expected)
+
+	c.Assert(mrseeker.Close(), IsNil)
+	_, err = child.Read(buf)
+	c.Check(errors.Is(err, os.ErrClosed), Equals, true)
+
+	_, err = MmapChild(names[0] + ".missing")
+	c.Check(os.IsNotExist(err), Equals, true)
+}
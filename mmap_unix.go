@@ -0,0 +1,123 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package multireadseeker
+
+// A child that reads a memory-mapped file.
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+type mmapChild struct {
+	name string
+	data []byte
+	pos  int64
+
+	closed bool
+}
+
+// MmapChild returns a child that reads the file at path through a
+// read-only, shared memory mapping of it, so that reads, and ReadAt,
+// are copies from memory, without a system call each time. This suits
+// files that are read many times. The file is mapped when MmapChild is
+// called, and unmapped by Close; it isn't kept open. MmapChild isn't
+// supported on Windows or Plan 9, where it returns an error.
+func MmapChild(path string) (ReadCloseSeeker, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close() // ignore any error; the mapping stays
+
+	fileinfo, err := fh.Stat()
+	if err != nil {
+		return nil, err
+	}
+	child := &mmapChild{name: path}
+
+	// A file can't be mapped with a length of 0
+	if fileinfo.Size() == 0 {
+		return child, nil
+	}
+	if int64(int(fileinfo.Size())) != fileinfo.Size() {
+		return nil, errors.Errorf("%s is too large to map: %d bytes",
+			path, fileinfo.Size())
+	}
+	child.data, err = unix.Mmap(int(fh.Fd()), 0, int(fileinfo.Size()),
+		unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Mapping %s", path)
+	}
+	return child, nil
+}
+
+func (self *mmapChild) Read(p []byte) (int, error) {
+	if self.closed {
+		return 0, os.ErrClosed
+	}
+	if self.pos >= int64(len(self.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, self.data[self.pos:])
+	self.pos += int64(n)
+	return n, nil
+}
+
+func (self *mmapChild) ReadAt(p []byte, off int64) (int, error) {
+	if self.closed {
+		return 0, os.ErrClosed
+	}
+	if off < 0 {
+		return 0, errors.Wrapf(ErrNegativeSeek, "ReadAt offset %d", off)
+	}
+	if off >= int64(len(self.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, self.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (self *mmapChild) Seek(offset int64, whence int) (int64, error) {
+	if self.closed {
+		return self.pos, os.ErrClosed
+	}
+	var newPos int64
+	switch whence {
+	case io.SeekStart:
+		newPos = offset
+	case io.SeekCurrent:
+		newPos = self.pos + offset
+	case io.SeekEnd:
+		newPos = int64(len(self.data)) + offset
+	default:
+		return self.pos, errors.Errorf(
+			"Seek(offset, whence); whence must be 0, 1, or 2, not %d", whence)
+	}
+	if newPos < 0 {
+		return self.pos, errors.Wrapf(ErrNegativeSeek, "Seek to %d", newPos)
+	}
+	self.pos = newPos
+	return self.pos, nil
+}
+
+func (self *mmapChild) Close() error {
+	if self.closed {
+		return os.ErrClosed
+	}
+	self.closed = true
+	if self.data == nil {
+		return nil
+	}
+	data := self.data
+	self.data = nil
+	return errors.Wrapf(unix.Munmap(data), "Unmapping %s", self.name)
+}
@@ -7,16 +7,25 @@ package multireadseeker
 // implements io.Read, io.ReaderAt, and io.Close
 
 import (
+	"fmt"
 	"io"
-	//    "os"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
 	"github.com/crewjam/errset"
 	"github.com/pkg/errors"
 )
 
+// The whence values for Seek. These are the same as io.SeekStart,
+// io.SeekCurrent, and io.SeekEnd, and either set can be used.
+//
+// Deprecated: use io.SeekStart, io.SeekCurrent, and io.SeekEnd.
 const (
-	WHENCE_START   = 0
-	WHENCE_CURRENT = 1
-	WHENCE_END     = 2
+	WHENCE_START   = io.SeekStart
+	WHENCE_CURRENT = io.SeekCurrent
+	WHENCE_END     = io.SeekEnd
 )
 
 // io.ReadSeeker
@@ -31,6 +40,88 @@ const (
 // io.Closer
 //        Close() error
 
+var (
+	// Initialize was called on a MultiReadSeeker that was already
+	// initialized.
+	ErrAlreadyInitialized = errors.New("MultiReadSeeker already initialized")
+
+	// Initialize was called without any children.
+	ErrNoChildren = errors.New("MultiReadSeeker needs at least one child")
+
+	// Close was called on a MultiReadSeeker that was already closed.
+	ErrAlreadyClosed = errors.New("MultiReadSeeker already closed")
+
+	// A child index was not in [0, NumChildren())
+	ErrIndexOutOfRange = errors.New("child index out of range")
+
+	// ChildByName was given a name that no child has.
+	ErrChildNotFound = errors.New("child not found")
+
+	// RestoreCheckpoint was given a checkpoint that can't be read, or
+	// that is for different children.
+	ErrCheckpointMismatch = errors.New("checkpoint doesn't match")
+
+	// Seek was given a positive offset relative to the end, or a
+	// position was beyond the end where it must be inside.
+	ErrSeekPastEnd = errors.New("seek past end")
+
+	// Seek, or ReadAt, was given a negative position.
+	ErrNegativeSeek = errors.New("negative position")
+
+	// Read, ReadAt, or Seek was called after Close.
+	ErrClosedSeeker = errors.New("MultiReadSeeker is closed")
+
+	// A child that was closed by WithCloseOnEOF was needed again.
+	ErrChildClosed = errors.New("child already closed")
+
+	// An empty child was added with WithEmptyChildPolicy(ErrorOnEmpty).
+	ErrEmptyChild = errors.New("child is empty")
+)
+
+// SeekError is the error when seeking a child fails. Use errors.As to
+// get it from an error.
+type SeekError struct {
+	// The index of the child
+	ChildIndex int
+	// The arguments to the child's Seek
+	Offset int64
+	Whence int
+	// The error from the child's Seek
+	Err error
+}
+
+func (self *SeekError) Error() string {
+	if self.Whence == io.SeekStart {
+		return fmt.Sprintf("Seeking io.Seeker #%d (0-based) to %d: %v",
+			self.ChildIndex, self.Offset, self.Err)
+	}
+	return fmt.Sprintf("Seeking io.Seeker #%d (0-based) to %d, whence %d: %v",
+		self.ChildIndex, self.Offset, self.Whence, self.Err)
+}
+
+func (self *SeekError) Unwrap() error {
+	return self.Err
+}
+
+// Cause is for errors.Cause from github.com/pkg/errors
+func (self *SeekError) Cause() error {
+	return self.Err
+}
+
+// Seek child i to childPos, returning a *SeekError if it fails.
+func seekChild(i int, child io.Seeker, childPos int64) error {
+	_, err := child.Seek(childPos, io.SeekStart)
+	if err != nil {
+		return &SeekError{
+			ChildIndex: i,
+			Offset:     childPos,
+			Whence:     io.SeekStart,
+			Err:        err,
+		}
+	}
+	return nil
+}
+
 type ReadCloseSeeker interface {
 	io.Reader
 	io.Seeker
@@ -38,18 +129,87 @@ type ReadCloseSeeker interface {
 }
 
 type MultiReadSeeker struct {
+	initialized bool
+	closed      bool
+
+	// When it was created, for Stat
+	created time.Time
+
 	children []ReadCloseSeeker
 
 	superPosStart []int64
-	// The superPos that the last position in the file
-	// represents. For a file that has 1 byte, the superPosStart
-	// and superPosEnd will be the same.
+	// The superPos one past the last position in the file, like
+	// the size of a file. For a file that has 1 byte, the
+	// superPosEnd is superPosStart + 1.
 	superPosEnd []int64
 
+	// Children with nothing to read are left out of 'children',
+	// but we still own them, so Close() has to close them.
+	emptyChildren []ReadCloseSeeker
+
+	// For children given to NewLazy, the function that opens the child.
+	// The child is nil in 'children' until it is opened.
+	openers []func() (ReadCloseSeeker, error)
+
+	// Children that failed, and that the WithErrorHandler handler said
+	// to skip. They aren't read again.
+	failed []bool
+
+	// The names of the children, from NewFromNamedChildren or
+	// SetChildName; "" if a child has no name
+	names []string
+
+	// The total size of all the children
+	size int64
+
+	// Set by NewWithOptions
+	options options
+
+	// The children belong to another MultiReadSeeker, as with Clone,
+	// so Close doesn't close them
+	borrowed bool
+
+	// Shared with the other MultiReadSeekers that have the same
+	// children, if there are any
+	shared *sharedChildren
+
+	// With WithReadAhead, the next child being opened in the
+	// background, if any, and the read-aheads that were cancelled and
+	// whose children are still to be closed
+	readAhead          *readAhead
+	cancelledReadAhead sync.WaitGroup
+
+	// With WithBufferSize, the buffers for the children
+	bufferPool *sync.Pool
+
+	// With WithMaxOpenFiles, the children that are open and can be
+	// opened again; nil until one is used
+	openChildren *openChildren
+
+	// Callbacks for events, from OnChildSwitch and the like
+	hooks hooks
+
+	// The counters for Stats
+	stats readStats
+
+	// For ReadByte and ReadRune, so they don't allocate
+	byteBuf [1]byte
+	runeBuf [utf8.UTFMax]byte
+	// The size of the rune from the last ReadRune, for UnreadRune;
+	// 0 if Read or Seek came after it
+	lastRuneSize int
+
 	currentSeekerNum int
 	currentSuperPos  int64
 }
 
+// MultiReadSeekers that share children keep track of which of them
+// positioned the children last; any other one has to put its current
+// child back where it expects it to be before it reads.
+type sharedChildren struct {
+	positioner *MultiReadSeeker
+}
+
 // Allocate and initialize a new MultiReadSeeker
 func New(children ...ReadCloseSeeker) (*MultiReadSeeker, error) {
 	mrseeker := &MultiReadSeeker{}
@@ -60,348 +220,795 @@ func New(children ...ReadCloseSeeker) (*MultiReadSeeker, error) {
 	return mrseeker, nil
 }
 
-// Initialize a newly-allocated MultiReadSeeker
+// NewWithOptions is like New, but the options change how the
+// MultiReadSeeker behaves.
+func NewWithOptions(opts []Option, children ...ReadCloseSeeker) (*MultiReadSeeker, error) {
+	mrseeker := &MultiReadSeeker{
+		options: *newOptions(opts),
+	}
+	err := mrseeker.Initialize(children...)
+	if err != nil {
+		return nil, err
+	}
+	return mrseeker, nil
+}
+
+// NewEmpty returns a MultiReadSeeker that has no children, for callers
+// that will Append the children as they become available. Until then,
+// Read returns io.EOF and Size returns 0.
+func NewEmpty() *MultiReadSeeker {
+	return &MultiReadSeeker{
+		initialized: true,
+		created:     time.Now(),
+	}
+}
+
+// Initialize a newly-allocated MultiReadSeeker. Children whose size
+// is 0 are skipped; they are not counted as children, but Close()
+// still closes them.
 func (self *MultiReadSeeker) Initialize(children ...ReadCloseSeeker) error {
-	if len(self.children) != 0 {
-		panic("MultiReadSeeker already initialized")
+	if self.initialized {
+		return ErrAlreadyInitialized
 	}
 	if len(children) == 0 {
-		panic("MultiReadSeeker needs at least one child")
+		return ErrNoChildren
 	}
+	self.initialized = true
+	self.created = time.Now()
+	return self.Append(children...)
+}
+
+// Find the size of a child, and leave it positioned at its start.
+func measureChild(child ReadCloseSeeker) (int64, error) {
+	// Go to the end of the seeker to find its size
+	childSize, err := child.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, errors.Wrapf(err, "Seeking to end of %v", child)
+	}
+	// Reposition to the beginning
+	_, err = child.Seek(0, io.SeekStart)
+	if err != nil {
+		return 0, errors.Wrapf(err, "Seeking to start of %v", child)
+	}
+	return childSize, nil
+}
 
-	self.children = make([]ReadCloseSeeker, len(children))
-	self.superPosStart = make([]int64, len(children))
-	self.superPosEnd = make([]int64, len(children))
+// Add a child to the end. The child is nil if opener will open it.
+func (self *MultiReadSeeker) appendChild(child ReadCloseSeeker, size int64,
+	opener func() (ReadCloseSeeker, error)) {
+	// This child starts where the previous child ends
+	self.children = append(self.children, child)
+	self.superPosStart = append(self.superPosStart, self.size)
+	self.size += size
+	self.superPosEnd = append(self.superPosEnd, self.size)
+	self.openers = append(self.openers, opener)
+	self.failed = append(self.failed, false)
+	self.names = append(self.names, "")
+}
 
+// A LazyChild is a child that isn't opened until reading reaches it.
+// Its Size must be known in advance.
+type LazyChild struct {
+	Size int64
+	Open func() (ReadCloseSeeker, error)
+}
+
+// NewLazy returns a MultiReadSeeker whose children are opened only when
+// a Read or Seek first reaches them. The total size comes from the
+// declared sizes, so no child is opened here. Children with a Size of 0
+// are never opened, or, with WithEmptyChildPolicy(ErrorOnEmpty), are an
+// error.
+func NewLazy(children []LazyChild) (*MultiReadSeeker, error) {
+	return NewLazyWithOptions(nil, children)
+}
+
+// NewLazyWithOptions is NewLazy with options, as for NewWithOptions.
+func NewLazyWithOptions(opts []Option, children []LazyChild) (*MultiReadSeeker, error) {
+	if len(children) == 0 {
+		return nil, ErrNoChildren
+	}
+	self := &MultiReadSeeker{
+		initialized: true,
+		created:     time.Now(),
+		options:     *newOptions(opts),
+	}
 	for i, child := range children {
-		self.children[i] = child
-		// Go to the end of the seeker
-		endPos, err := child.Seek(0, WHENCE_END)
-		if err != nil {
-			return errors.Wrapf(err, "Seeking to end of %v", child)
+		if child.Size < 0 {
+			return nil, errors.Errorf("LazyChild #%d (0-based) has negative size %d",
+				i, child.Size)
 		}
-		self.superPosEnd[i] = endPos
-		// This file starts after the previous file ends
-		if i > 0 {
-			self.superPosStart[i] = self.superPosEnd[i-1] + 1
+		if child.Size == 0 {
+			if self.options.emptyChildPolicy == ErrorOnEmpty {
+				return nil, errors.Wrapf(ErrEmptyChild, "LazyChild #%d (0-based)", i)
+			}
+			continue
 		}
-		// Reposition to the beginning
-		_, err = child.Seek(0, WHENCE_START)
-		if err != nil {
-			return errors.Wrapf(err, "Seeking to start of %v", child)
+		self.appendChild(nil, child.Size, child.Open)
+	}
+	return self, nil
+}
+
+// Return the child at index i, opening it first if it came from
+// NewLazy and hasn't been opened yet, or was closed by WithCloseOnEOF
+// or WithMaxOpenFiles.
+func (self *MultiReadSeeker) child(i int) (ReadCloseSeeker, error) {
+	if self.children[i] != nil {
+		self.usedChild(i)
+		return self.children[i], nil
+	}
+	if self.openers[i] == nil {
+		return nil, errors.Wrapf(ErrChildClosed, "io.Seeker #%d (0-based)", i)
+	}
+	var child ReadCloseSeeker
+	var err error
+	if self.readAhead != nil && self.readAhead.index == i {
+		child, err = self.takeReadAhead()
+	} else {
+		child, err = openLazyChild(i, self.openers[i])
+	}
+	if err != nil {
+		return nil, err
+	}
+	child = self.wrapChild(child)
+	self.children[i] = child
+	self.usedChild(i)
+	return child, nil
+}
+
+// Open the child at index i with opener, and position it at its start,
+// as the children given to New are.
+func openLazyChild(i int, opener func() (ReadCloseSeeker, error)) (ReadCloseSeeker, error) {
+	child, err := opener()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Opening io.Seeker #%d (0-based)", i)
+	}
+	err = seekChild(i, child, 0)
+	if err != nil {
+		child.Close() // ignore any error
+		return nil, err
+	}
+	return child, nil
+}
+
+// Wrap a child that is positioned at its start, as the options ask
+func (self *MultiReadSeeker) wrapChild(child ReadCloseSeeker) ReadCloseSeeker {
+	if self.options.bufferSize > 0 {
+		if self.bufferPool == nil {
+			self.bufferPool = newBufferPool(self.options.bufferSize)
 		}
+		child = newBufferedChild(child, self.bufferPool, self.options.bufferSize)
 	}
-	return nil
+	return child
 }
 
+// Close all the children. The children are closed only once; calling
+// Close again returns ErrAlreadyClosed.
 func (self *MultiReadSeeker) Close() error {
+	if self.closed {
+		return ErrAlreadyClosed
+	}
+	self.closed = true
+	self.cancelReadAhead()
+	self.cancelledReadAhead.Wait()
+	if self.borrowed {
+		return nil
+	}
+
+	// Close the children in parallel, since each Close may be slow,
+	// like closing a network stream. Keep the errors in child order.
+	children := append(append([]ReadCloseSeeker{}, self.children...),
+		self.emptyChildren...)
+	closeErrs := make([]error, len(children))
+	var wg sync.WaitGroup
+	for i, child := range children {
+		if child == nil {
+			// Never opened, or already closed
+			continue
+		}
+		wg.Add(1)
+		go func(i int, child ReadCloseSeeker) {
+			defer wg.Done()
+			closeErrs[i] = child.Close()
+		}(i, child)
+	}
+	wg.Wait()
+
 	errs := errset.ErrSet{}
-	for i, child := range self.children {
-		err := child.Close()
-		if err != nil {
+	for i, err := range closeErrs {
+		if err == nil {
+			continue
+		}
+		if i < len(self.children) {
+			err = errors.Wrapf(err, "Closing io.Seeker #%d (0-based)", i)
+			self.hooks.childError(i, err)
+			errs = append(errs, err)
+		} else {
 			errs = append(errs,
-				errors.Wrapf(err, "Closing io.Seeker #%d (0-based)", i))
+				errors.Wrapf(err, "Closing empty io.Seeker %v", children[i]))
 		}
 	}
 	// nil if there were no errors
 	return errs.ReturnValue()
 }
 
-/*
+// Close the child at index i before the MultiReadSeeker is closed.
+// Its slot is set to nil so that Close doesn't close it again. A
+// borrowed child is only dropped, not closed.
+func (self *MultiReadSeeker) closeChild(i int) error {
+	self.openChildren.remove(i)
+	child := self.children[i]
+	if child == nil {
+		return nil
+	}
+	self.children[i] = nil
+	if self.borrowed {
+		return nil
+	}
+	err := child.Close()
+	if err != nil {
+		err = errors.Wrapf(err, "Closing io.Seeker #%d (0-based)", i)
+		self.hooks.childError(i, err)
+	}
+	return err
+}
 
-type ConcatFile struct {
-    // name given to Open() by caller
-//    public_name         string
+// Read up to len(p) bytes. When the current io.Seeker is exhausted,
+// reading continues with the next one, so a single Read can return
+// bytes from more than one child.
+func (self *MultiReadSeeker) Read(p []byte) (n int, err error) {
+	if self.closed {
+		return 0, ErrClosedSeeker
+	}
+	if len(self.options.observers) > 0 {
+		finish := self.startRead(len(p))
+		defer func() { finish(n, err) }()
+	}
+	self.lastRuneSize = 0
+	atEnd := self.currentSuperPos >= self.size
+	n, err = self.read(p)
+	self.stats.read(n)
+	if !atEnd && self.currentSuperPos >= self.size {
+		self.hooks.reachedEOF()
+	}
+	return n, err
+}
 
-    // Is the ConcatFile currently open?
-    // We mark it as closed after an unrecoverable error
-    currently_open      bool
+func (self *MultiReadSeeker) read(p []byte) (int, error) {
+	err := self.ctxErr()
+	if err != nil {
+		return 0, err
+	}
+	err = self.reclaimChildren()
+	if err != nil {
+		return 0, err
+	}
 
-    // All the filenames that make up the complete data set.
-    filenames           []string
+	numRead := 0
+	for len(p) > 0 {
+		if self.currentSuperPos >= self.size {
+			if numRead == 0 {
+				return 0, io.EOF
+			}
+			break
+		}
 
-    // Number of files (length of 'filenames')
-    num_files           int
+		// Go to the next child if we are at the end of this one
+		if self.currentSuperPos == self.superPosEnd[self.currentSeekerNum] {
+			err = self.ctxErr()
+			if err != nil {
+				if numRead > 0 {
+					break
+				}
+				return 0, err
+			}
+			err = self.nextChild()
+			if err != nil {
+				return numRead, err
+			}
+		}
 
-    // The currently open file number
-    current_file_num    int
+		// Don't read beyond the size we measured for this child
+		buf := p
+		childRemaining := self.superPosEnd[self.currentSeekerNum] - self.currentSuperPos
+		if int64(len(buf)) > childRemaining {
+			buf = buf[:childRemaining]
+		}
 
-    // The currently opened filehandle, and the position within it
-    fh                  *os.File
+		if self.failed[self.currentSeekerNum] {
+			n := self.skipFailed(buf)
+			numRead += n
+			p = p[n:]
+			continue
+		}
 
-    // Our position (across the sequence of all files)
-    super_pos           int64
+		child, err := self.child(self.currentSeekerNum)
+		if err != nil {
+			if self.skipChild(self.currentSeekerNum, err) {
+				continue
+			}
+			return numRead, err
+		}
+		n, err := child.Read(buf)
+		self.stats.childRead(self.currentSeekerNum, n)
+		numRead += n
+		p = p[n:]
+		self.currentSuperPos += int64(n)
+		self.startReadAhead()
+
+		if err == io.EOF {
+			// io.EOF with n > 0 at the end of the child is normal;
+			// keep going with the next child. But if the child ended
+			// before the size that we measured, return what we have,
+			// and report the problem on the next Read.
+			if self.currentSuperPos < self.superPosEnd[self.currentSeekerNum] {
+				if numRead > 0 {
+					break
+				}
+				err = errors.Wrapf(io.ErrUnexpectedEOF,
+					"Reading io.Seeker #%d (0-based)", self.currentSeekerNum)
+				if self.skipChild(self.currentSeekerNum, err) {
+					continue
+				}
+				return 0, err
+			}
+		} else if err != nil {
+			err = errors.Wrapf(err,
+				"Reading io.Seeker #%d (0-based)", self.currentSeekerNum)
+			if self.skipChild(self.currentSeekerNum, err) {
+				continue
+			}
+			return numRead, err
+		} else if n == 0 {
+			// The child gave us nothing; let the caller try again
+			break
+		}
+	}
+	return numRead, nil
+}
 
-    // Our complete size (across the sequence of all files)
-    super_size          int64
+// The error from the WithContext context, if it is done
+func (self *MultiReadSeeker) ctxErr() error {
+	if self.options.ctx == nil {
+		return nil
+	}
+	return self.options.ctx.Err()
+}
 
-    // Position/size info for all the files
-    super_pos_start     []int64
-    super_pos_end       []int64
+// Move from the current child, which Read has reached the end of,
+// to the start of the next one.
+func (self *MultiReadSeeker) nextChild() error {
+	nextSeekerNum := self.currentSeekerNum + 1
+	if !self.failed[nextSeekerNum] {
+		child, err := self.child(nextSeekerNum)
+		if err == nil {
+			self.stats.childSeeked(nextSeekerNum)
+			err = seekChild(nextSeekerNum, child, 0)
+		}
+		if err != nil && !self.skipChild(nextSeekerNum, err) {
+			return err
+		}
+	}
+	if self.options.closeOnEOF {
+		err := self.closeChild(self.currentSeekerNum)
+		if err != nil {
+			return err
+		}
+	} else if buffered, ok := self.children[self.currentSeekerNum].(interface{ release() }); ok {
+		// The child's buffer isn't needed until it is read again
+		buffered.release()
+	}
+	self.currentSeekerNum = nextSeekerNum
+	self.stats.childSwitched()
+	self.hooks.childSwitched(nextSeekerNum-1, nextSeekerNum)
+	self.observeChildSwitch(nextSeekerNum-1, nextSeekerNum)
+	return nil
 }
 
-//
-// Open returns a new ConcatFile, similar to os.Open(), except
-// that the input is a slice of file names, as opposed to just one.
-//
-func Open(names []string) (self *ConcatFile, err error)  {
-    // Ensure we have at least one file
-    if len(names) == 0 {
-        return nil, errors.New("At least one file name is required")
-    }
-
-    // Create the new object and analyze the files to
-    // fill in the object data
-    self = new(ConcatFile)
-
-    // Fill in initial data
-    self.filenames = names
-    self.num_files = len(names)
-    self.current_file_num = 0
-    self.super_pos_start = make([]int64, self.num_files)
-    self.super_pos_end = make([]int64, self.num_files)
-    self.super_pos_start[0] = 0
-
-    // Go through each file and find its size
-    for i := 0 ; i < self.num_files ; i++ {
-
-        // Any permission problems?
-        fh, err := os.Open(names[i])
-        if err != nil {
-            return nil, err
-        }
-
-        // This file starts after the previous file ends
-        if i > 0 {
-            self.super_pos_start[i] = self.super_pos_end[i - 1] + 1
-        }
-
-        // stat the file
-        fileinfo, err := fh.Stat()
-        fh.Close() // ignore any error
-        if err != nil {
-            return nil, err
-        }
-
-        // Mark the end of this file
-        self.super_pos_end[i] = self.super_pos_start[i] + fileinfo.Size() - 1
-    }
-    // Our super-size
-    self.super_size = self.super_pos_end[self.num_files - 1] + 1
-
-    // Open the first file
-    self.fh, err = os.Open(names[0])
-    if err != nil {
-        return nil, err
-    }
-//    self.fh_pos = 0
-    self.currently_open = true
-
-    return self, nil
+// Ask the WithErrorHandler handler whether to skip the child at index
+// i because of err. If so, the child is marked as failed.
+func (self *MultiReadSeeker) skipChild(i int, err error) bool {
+	self.hooks.childError(i, err)
+	if self.options.errorHandler == nil || !self.options.errorHandler(i, err) {
+		return false
+	}
+	self.failed[i] = true
+	return true
 }
 
-//
-// Close() a ConcatFile
-//
-func (self *ConcatFile) Close() (err error)  {
-    if self.currently_open {
-        self.currently_open = false
-        return self.fh.Close()
-    } else {
-        return nil
-    }
+// "Read" buf, which doesn't go past the end of the current child,
+// from the current child, which has failed. Its bytes are zeros with
+// WithZeroFill; otherwise Read skips them and doesn't return them.
+func (self *MultiReadSeeker) skipFailed(buf []byte) int {
+	if !self.options.zeroFill {
+		self.currentSuperPos = self.superPosEnd[self.currentSeekerNum]
+		return 0
+	}
+	for i := range buf {
+		buf[i] = 0
+	}
+	self.currentSuperPos += int64(len(buf))
+	return len(buf)
 }
 
-//
-// Read len(b) bytes
-// See:
-// http://golang.org/pkg/io/#Reader
-//
-func (self *ConcatFile) Read(b []byte) (n int, err error) {
+// Seek sets the offset for the next Read, interpreted according to whence:
+// io.SeekStart means relative to the start of the first child,
+// io.SeekCurrent means relative to the current offset, and
+// io.SeekEnd means relative to the end of the last child.
+// It returns the new offset and an error, if any.
+func (self *MultiReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	if self.closed {
+		return self.currentSuperPos, ErrClosedSeeker
+	}
+	pos, err := self.seek(offset, whence)
+	self.observeSeek(pos, err)
+	return pos, err
+}
 
-    if self.currently_open == false {
-        return 0, io.ErrUnexpectedEOF // XXX what should I really return here?
-    }
-    num_requested := len(b)
+func (self *MultiReadSeeker) seek(offset int64, whence int) (int64, error) {
+	self.stats.seeked()
+	self.lastRuneSize = 0
+	lastSeekerNum := len(self.children) - 1
+
+	var newSuperPos int64
+	switch whence {
+	case io.SeekStart:
+		newSuperPos = offset
+	case io.SeekCurrent:
+		newSuperPos = self.currentSuperPos + offset
+	case io.SeekEnd:
+		if offset > 0 {
+			return self.currentSuperPos, errors.Wrapf(ErrSeekPastEnd,
+				"Seek(%d, io.SeekEnd) with size %d; offset must be <= 0",
+				offset, self.size)
+		}
+		newSuperPos = self.size + offset
+	default:
+		return self.currentSuperPos,
+			errors.Errorf("Seek(offset, whence); whence must be 0, 1, or 2, not %d", whence)
+	}
 
-    n, err = self.fh.Read(b)
-    self.super_pos += int64(n)
+	if newSuperPos < 0 {
+		return self.currentSuperPos,
+			errors.Wrapf(ErrNegativeSeek, "Seek to %d", newSuperPos)
+	}
 
-    // Did we read the exact amount?
-    if n == num_requested {
-        return n, err
-    }
+	seekIndex := self.findSeekIndex(newSuperPos)
+	if self.readAhead != nil && self.readAhead.index != seekIndex {
+		// Read won't get to the next child yet
+		self.cancelReadAhead()
+	}
+	if seekIndex == seekImpossible {
+		// At or beyond the end; there's nothing to read, so the
+		// children don't need to be repositioned.
+		if lastSeekerNum >= 0 {
+			self.currentSeekerNum = lastSeekerNum
+		}
+		self.currentSuperPos = newSuperPos
+		return self.currentSuperPos, nil
+	}
 
-    // Don't bother checking n > num_requested; it's impossible.
+	// Seek to the absolute position in the correct child. A child
+	// that has failed isn't read, so it doesn't need to be seeked.
+	if !self.failed[seekIndex] {
+		childPos := newSuperPos - self.superPosStart[seekIndex]
+		child, err := self.child(seekIndex)
+		if err == nil {
+			self.stats.childSeeked(seekIndex)
+			err = seekChild(seekIndex, child, childPos)
+		}
+		if err != nil && !self.skipChild(seekIndex, err) {
+			return self.currentSuperPos, err
+		}
+	}
+	self.currentSeekerNum = seekIndex
+	self.currentSuperPos = newSuperPos
+	if self.children[seekIndex] != nil {
+		// The child that was current can be closed now
+		self.usedChild(seekIndex)
+	}
+	self.claimChildren()
+	return self.currentSuperPos, nil
+}
 
-    // We got less than num_requested.  Is there another file to go to?
-    incremented, incr_err := self.goToNextFile()
-    if incr_err != nil {
-        // Had a problem opening the next file. Return an error
-        return n, incr_err
-    }
+// Reset goes back to the start, like Seek(0, io.SeekStart), but also
+// seeks every child that has been opened back to its start, and drops
+// what was kept from before: the rune for UnreadRune, and a child being
+// opened by WithReadAhead. A child that WithCloseOnEOF closed is
+// reopened if it came from NewLazy; the first child is reopened here,
+// and the others when Read gets to them. A closed child that can't be
+// reopened makes Reset return ErrChildClosed.
+func (self *MultiReadSeeker) Reset() error {
+	if self.closed {
+		return ErrClosedSeeker
+	}
+	self.lastRuneSize = 0
+	self.cancelReadAhead()
+	for i, child := range self.children {
+		if self.failed[i] {
+			continue
+		}
+		if child == nil {
+			if self.openers[i] == nil {
+				return errors.Wrapf(ErrChildClosed, "io.Seeker #%d (0-based)", i)
+			}
+			if i > 0 {
+				// Opened at its start when Read gets to it
+				continue
+			}
+			// Opened at its start
+			_, err := self.child(i)
+			if err != nil {
+				self.hooks.childError(i, err)
+				return err
+			}
+			continue
+		}
+		self.stats.childSeeked(i)
+		err := seekChild(i, child, 0)
+		if err != nil {
+			self.hooks.childError(i, err)
+			return err
+		}
+	}
+	self.currentSeekerNum = 0
+	self.currentSuperPos = 0
+	self.claimChildren()
+	return nil
+}
 
-    if incremented {
-        // Read some more from the next file
-        sub_n, sub_err := self.Read(b[n:])
-        return n + sub_n, sub_err
+// Tell returns the current position, the same value that
+// Seek(0, io.SeekCurrent) would return. It doesn't access any of the
+// children, so it is safe to call after Close, but the value returned
+// then is undefined.
+func (self *MultiReadSeeker) Tell() int64 {
+	return self.currentSuperPos
+}
 
-    } else if n > 0 {
-        // No other file. Return what we got.
-        return n, err
+// Size returns the total size of all the children.
+func (self *MultiReadSeeker) Size() int64 {
+	return self.size
+}
 
-    } else {
-        // We read nothing and there's no other file. Return EOF
-        return n, io.EOF
-    }
+// Remaining returns the number of bytes from the current position to
+// the end; 0 at or beyond the end.
+func (self *MultiReadSeeker) Remaining() int64 {
+	if self.currentSuperPos >= self.size {
+		return 0
+	}
+	return self.size - self.currentSuperPos
+}
 
+// NumChildren returns the number of children. Children that were
+// skipped because they were empty are not counted.
+func (self *MultiReadSeeker) NumChildren() int {
+	return len(self.children)
 }
 
-//
-// Close the current file and go to the next file if possible.
-// Returns (true, err), or (false, nil)
-//
-func (self *ConcatFile) goToNextFile() (success bool, err error) {
+// ChildAt returns the child at index i, opening it if it came from
+// NewLazy. With WithBufferSize, this is the child wrapped in its
+// buffer. The child still belongs to the MultiReadSeeker; reading or
+// seeking it directly moves it out from under the MultiReadSeeker,
+// which then reads from the wrong place.
+func (self *MultiReadSeeker) ChildAt(i int) (ReadCloseSeeker, error) {
+	err := self.checkChildIndex(i)
+	if err != nil {
+		return nil, err
+	}
+	return self.child(i)
+}
 
-    // Any more files?
-    if self.current_file_num < self.num_files - 1 {
-        // Close the current file
-        self.fh.Close()
+// ChildSizeAt returns the size of the child at index i.
+func (self *MultiReadSeeker) ChildSizeAt(i int) (int64, error) {
+	err := self.checkChildIndex(i)
+	if err != nil {
+		return 0, err
+	}
+	return self.superPosEnd[i] - self.superPosStart[i], nil
+}
 
-        // Open the next file
-        self.current_file_num++
-        self.fh, err = os.Open(self.filenames[self.current_file_num])
-        return true, err
-    } else {
-        return false, nil
-    }
+// ChildStartPos returns the position where the child at index i starts.
+func (self *MultiReadSeeker) ChildStartPos(i int) (int64, error) {
+	err := self.checkChildIndex(i)
+	if err != nil {
+		return 0, err
+	}
+	return self.superPosStart[i], nil
 }
 
+// ChildEndPos returns the position one past the last byte of the child
+// at index i, which is where the next child starts.
+func (self *MultiReadSeeker) ChildEndPos(i int) (int64, error) {
+	err := self.checkChildIndex(i)
+	if err != nil {
+		return 0, err
+	}
+	return self.superPosEnd[i], nil
+}
 
-const WHENCE_ORIGIN int = 0
-const WHENCE_CURRENT int = 1
-const WHENCE_END int = 2
+// ChildSizes returns the sizes of all the children, in order.
+func (self *MultiReadSeeker) ChildSizes() []int64 {
+	sizes := make([]int64, len(self.children))
+	for i := range sizes {
+		sizes[i] = self.superPosEnd[i] - self.superPosStart[i]
+	}
+	return sizes
+}
 
-// Seek sets the offset for the next Read or Write on file to offset,
-// interpreted according to whence:
-//  0 means relative to the origin of the file,
-//  1 means relative to the current offset, and
-//  2 means relative to the end.
-// It returns the new offset and an error, if any.
-func (self *ConcatFile) Seek(offset int64, whence int) (ret int64, err error) {
-
-    var new_super_pos int64
-    var seek_index int
-
-    // Calculate the new super position
-    if whence == WHENCE_ORIGIN {
-        new_super_pos = offset
-        // Which file will that be in?
-        if new_super_pos == 0 {
-            seek_index = 0
-        } else {
-            seek_index = self.findSeekIndex(new_super_pos)
-        }
-
-    } else if whence == WHENCE_CURRENT {
-        // Special case: no change
-        if offset == 0 {
-            return self.super_pos, nil
-        }
-        new_super_pos = self.super_pos + offset
-
-        // Which file will that be in?
-        seek_index = self.findSeekIndex(new_super_pos)
-
-    } else if whence == WHENCE_END {
-        // offset must be 0 or negative
-        if offset > 0 {
-            return self.super_pos, errors.New("Seek(offset, WHENCE_END); seek must be <= 0")
-        }
-        new_super_pos = self.super_size + offset
-
-        // Which file will that be in?
-        seek_index = self.findSeekIndex(new_super_pos)
-
-    } else {
-        return self.super_pos, errors.New("Seek(offset, whence); whence must be 0, 1, or 2")
-    }
-
-    // If it's beyond our max limit, go to the last file, unless
-    // we were going backwards (WHENCE_END); in that case, go to the
-    // first file
-    if seek_index == seekImpossible {
-        if whence == WHENCE_END {
-            seek_index = 0
-        } else {
-            seek_index = self.num_files - 1
-        }
-    }
-
-    // Do we need to change files?
-    if seek_index != self.current_file_num {
-        // Close the current one
-        err = self.fh.Close()
-        if err != nil {
-            self.currently_open = false
-            return self.super_pos, err
-        }
-
-        // Open the correct one
-        self.fh, err = os.Open(self.filenames[seek_index])
-        if err != nil {
-            self.currently_open = false
-            return self.super_pos, err
-        }
-        self.current_file_num = seek_index
-    }
-
-    // Seek to the absolute position in the correct file
-    offset = new_super_pos - self.super_pos_start[seek_index]
-    this_file_offset, err := self.fh.Seek(offset, WHENCE_ORIGIN)
-    if err != nil {
-        self.currently_open = false
-        self.super_pos = self.super_pos_start[seek_index] + this_file_offset
-        return self.super_pos, err
-    }
-    self.super_pos = new_super_pos
-    return self.super_pos, nil
+// PositionToChild returns the index of the child that holds the byte
+// at position pos, and the offset of that byte within the child; that
+// is, the offset to give to the child's own Seek. It returns an error
+// if pos is negative or not less than Size().
+func (self *MultiReadSeeker) PositionToChild(pos int64) (int, int64, error) {
+	if pos < 0 {
+		return 0, 0, errors.Wrapf(ErrNegativeSeek, "Position %d", pos)
+	}
+	seekIndex := self.findSeekIndex(pos)
+	if seekIndex == seekImpossible {
+		return 0, 0, errors.Wrapf(ErrSeekPastEnd,
+			"Position %d is beyond the end (size %d)", pos, self.size)
+	}
+	return seekIndex, pos - self.superPosStart[seekIndex], nil
 }
 
+// CurrentChildIndex returns the index of the child that the current
+// position is in. At or beyond the end, it is the last child.
+func (self *MultiReadSeeker) CurrentChildIndex() int {
+	return self.currentSeekerNum
+}
 
-const seekImpossible int = -1
-//
-// Given a super position, return the index of the file where that
-// index will be located. If we have no such file, return seekImpossible (-1)
-//
-func (self *ConcatFile) findSeekIndex(new_super_pos int64) (index int) {
+// CurrentChildOffset returns the current position relative to the start
+// of the current child.
+func (self *MultiReadSeeker) CurrentChildOffset() int64 {
+	if len(self.children) == 0 {
+		return self.currentSuperPos
+	}
+	return self.currentSuperPos - self.superPosStart[self.currentSeekerNum]
+}
 
-    // The super position must not be negative
-    if new_super_pos < 0 {
-        return seekImpossible
-    }
+func (self *MultiReadSeeker) checkChildIndex(i int) error {
+	if i < 0 || i >= len(self.children) {
+		return errors.Wrapf(ErrIndexOutOfRange, "Child #%d (0-based) of %d",
+			i, len(self.children))
+	}
+	return nil
+}
 
-    // Go through each file and examine the boundaries
-    for i := 0 ; i < self.num_files ; i++ {
-        if new_super_pos >= self.super_pos_start[i] &&
-            new_super_pos <= self.super_pos_end[i] {
-            return i
-        }
-    }
+// ReadAt reads len(p) bytes starting at offset off, following the
+// io.ReaderAt contract. It does not change the position used by Read and
+// Seek. Children that implement io.ReaderAt are read directly; any other
+// child is seeked and read, and then the current child is put back where
+// it was.
+func (self *MultiReadSeeker) ReadAt(p []byte, off int64) (int, error) {
+	if self.closed {
+		return 0, ErrClosedSeeker
+	}
+	if off < 0 {
+		return 0, errors.Wrapf(ErrNegativeSeek, "ReadAt offset %d", off)
+	}
+	numRead := 0
+	disturbed := false
+	var err error
+	for len(p) > 0 {
+		if off >= self.size {
+			err = io.EOF
+			break
+		}
+		seekIndex := self.findSeekIndex(off)
+		childPos := off - self.superPosStart[seekIndex]
+
+		// Don't read beyond the size we measured for this child
+		buf := p
+		childRemaining := self.superPosEnd[seekIndex] - off
+		if int64(len(buf)) > childRemaining {
+			buf = buf[:childRemaining]
+		}
+
+		var n int
+		var child ReadCloseSeeker
+		child, err = self.child(seekIndex)
+		if err != nil {
+			self.hooks.childError(seekIndex, err)
+			break
+		}
+		if readerAt, ok := child.(io.ReaderAt); ok {
+			n, err = readerAt.ReadAt(buf, childPos)
+		} else {
+			disturbed = true
+			self.stats.childSeeked(seekIndex)
+			_, err = child.Seek(childPos, io.SeekStart)
+			if err == nil {
+				n, err = io.ReadFull(child, buf)
+			}
+		}
+		numRead += n
+		p = p[n:]
+		off += int64(n)
+
+		if n == len(buf) {
+			// A full read may still report io.EOF at the end of the child
+			err = nil
+		} else {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			err = errors.Wrapf(err, "Reading io.Seeker #%d (0-based) at %d",
+				seekIndex, childPos)
+			self.hooks.childError(seekIndex, err)
+			break
+		}
+	}
 
-    // Beyond the end?
-    return seekImpossible
+	if disturbed {
+		restoreErr := self.restoreChildPosition()
+		if err == nil {
+			err = restoreErr
+		}
+	}
+	return numRead, err
 }
 
-//
-// Technically this should check for self.currently_open,
-// be we already know the position and we don't have to
-// access the underlying filehandle, so just return the value.
-func (self *ConcatFile) Tell() (int64) {
-    return self.super_pos
+// Seek the current child back to the position that Read expects it
+// to be at.
+func (self *MultiReadSeeker) restoreChildPosition() error {
+	if self.currentSuperPos >= self.size {
+		// Read doesn't use the children at or beyond the end
+		self.claimChildren()
+		return nil
+	}
+	child := self.children[self.currentSeekerNum]
+	if child == nil || self.failed[self.currentSeekerNum] {
+		// Not opened yet, it will start where Read expects it;
+		// or Read won't read it again
+		self.claimChildren()
+		return nil
+	}
+	childPos := self.currentSuperPos - self.superPosStart[self.currentSeekerNum]
+	self.stats.childSeeked(self.currentSeekerNum)
+	err := seekChild(self.currentSeekerNum, child, childPos)
+	if err != nil {
+		self.hooks.childError(self.currentSeekerNum, err)
+		return err
+	}
+	self.claimChildren()
+	return nil
+}
+
+// Note that the children, if they are shared, were last positioned
+// for this MultiReadSeeker.
+func (self *MultiReadSeeker) claimChildren() {
+	if self.shared != nil {
+		self.shared.positioner = self
+	}
+}
+
+// If another MultiReadSeeker that shares the children has moved them,
+// put the current child back where Read expects it to be.
+func (self *MultiReadSeeker) reclaimChildren() error {
+	if self.shared == nil || self.shared.positioner == self {
+		return nil
+	}
+	return self.restoreChildPosition()
 }
 
-func (self *ConcatFile) Size() (int64) {
-    return self.super_size
+const seekImpossible int = -1
+
+// Given a super position, return the index of the child where that
+// position will be located. If we have no such child, return
+// seekImpossible (-1)
+func (self *MultiReadSeeker) findSeekIndex(superPos int64) int {
+	// The super position must not be negative
+	if superPos < 0 {
+		return seekImpossible
+	}
+
+	// The first child that ends after superPos; the ends are in order
+	i := sort.Search(len(self.children), func(i int) bool {
+		return self.superPosEnd[i] > superPos
+	})
+
+	// Beyond the end?
+	if i == len(self.children) {
+		return seekImpossible
+	}
+	return i
 }
-*/
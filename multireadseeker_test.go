@@ -1,10 +1,15 @@
 package multireadseeker
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 
+	"github.com/pkg/errors"
 	. "gopkg.in/check.v1"
 )
 
@@ -23,11 +28,1043 @@ func (s *MySuite) TestOneReader(c *C) {
 	mrseeker, err := New(file)
 	c.Assert(err, IsNil)
 	c.Assert(mrseeker, NotNil)
+	c.Check(mrseeker.Size(), Equals, int64(26))
 
-        // Seek
+	// Read
+	buf := make([]byte, 5)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(n, Equals, 5)
+	c.Check(string(buf), Equals, "ABCDE")
+
+	// Seek
+	pos, err := mrseeker.Seek(10, WHENCE_START)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(10))
+	n, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "KLMNO")
+	c.Check(mrseeker.Tell(), Equals, int64(15))
+
+	pos, err = mrseeker.Seek(-2, WHENCE_CURRENT)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(13))
+	n, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "NOPQR")
+
+	pos, err = mrseeker.Seek(-3, WHENCE_END)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(23))
+	n, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "XYZ")
+
+	// At the end
+	n, err = mrseeker.Read(buf)
+	c.Check(n, Equals, 0)
+	c.Check(err, Equals, io.EOF)
+
+	// Invalid seeks
+	_, err = mrseeker.Seek(-1, WHENCE_START)
+	c.Check(err, NotNil)
+	_, err = mrseeker.Seek(0, 3)
+	c.Check(err, NotNil)
+
+	// Close
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Tell(), Equals, int64(26))
+
+}
+
+// A ReadCloseSeeker that does not implement io.ReaderAt, so that
+// MultiReadSeeker has to seek it.
+type seekOnlyChild struct {
+	r          *bytes.Reader
+	closeCalls int
+}
+
+func newSeekOnlyChild(data string) *seekOnlyChild {
+	return &seekOnlyChild{r: bytes.NewReader([]byte(data))}
+}
+
+func (self *seekOnlyChild) Read(p []byte) (int, error) {
+	return self.r.Read(p)
+}
+
+func (self *seekOnlyChild) Seek(offset int64, whence int) (int64, error) {
+	return self.r.Seek(offset, whence)
+}
+
+func (self *seekOnlyChild) Close() error {
+	self.closeCalls++
+	return nil
+}
+
+func (s *MySuite) TestReadAt(c *C) {
+	mrseeker, err := New(newSeekOnlyChild("ABCDEFGHIJKLMNOPQRSTUVWXYZ"))
+	c.Assert(err, IsNil)
+
+	buf := make([]byte, 3)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "ABC")
+
+	// ReadAt doesn't move the Read position
+	buf = make([]byte, 4)
+	n, err = mrseeker.ReadAt(buf, 20)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "UVWX")
+
+	buf = make([]byte, 3)
+	n, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "DEF")
+
+	// Short read at the end
+	buf = make([]byte, 4)
+	n, err = mrseeker.ReadAt(buf, 24)
+	c.Check(err, Equals, io.EOF)
+	c.Check(string(buf[:n]), Equals, "YZ")
+
+	// Past the end
+	n, err = mrseeker.ReadAt(buf, 30)
+	c.Check(n, Equals, 0)
+	c.Check(err, Equals, io.EOF)
+
+	_, err = mrseeker.ReadAt(buf, -1)
+	c.Check(err, NotNil)
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+
+	// Several children, sizes 3, 4 and 2
+	mrseeker, err = New(newSeekOnlyChild("ABC"), newSeekOnlyChild("DEFG"),
+		newSeekOnlyChild("HI"))
+	c.Assert(err, IsNil)
+
+	// Within one child
+	buf = make([]byte, 2)
+	n, err = mrseeker.ReadAt(buf, 4)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "EF")
+
+	// Across a boundary
+	buf = make([]byte, 3)
+	n, err = mrseeker.ReadAt(buf, 2)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "CDE")
+
+	// Across all of them
+	buf = make([]byte, 9)
+	n, err = mrseeker.ReadAt(buf, 0)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "ABCDEFGHI")
+
+	// Ending exactly at a boundary
+	buf = make([]byte, 4)
+	n, err = mrseeker.ReadAt(buf, 3)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "DEFG")
+
+	// Ending exactly at the end
+	buf = make([]byte, 3)
+	n, err = mrseeker.ReadAt(buf, 6)
+	c.Check(err, Equals, nil)
+	c.Check(string(buf[:n]), Equals, "GHI")
+
+	// Short read across a boundary to the end
+	buf = make([]byte, 5)
+	n, err = mrseeker.ReadAt(buf, 6)
+	c.Check(err, Equals, io.EOF)
+	c.Check(string(buf[:n]), Equals, "GHI")
+
+	// At and past the end
+	n, err = mrseeker.ReadAt(buf, 9)
+	c.Check(n, Equals, 0)
+	c.Check(err, Equals, io.EOF)
+	n, err = mrseeker.ReadAt(buf, 10)
+	c.Check(n, Equals, 0)
+	c.Check(err, Equals, io.EOF)
+
+	// None of it moved the Read position
+	c.Check(mrseeker.Tell(), Equals, int64(0))
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestTwoFilesNoGap(c *C) {
+	// Sizes 3 and 5
+	dataFile1 := filepath.Join(s.tmpDir, "nogap1")
+	err := ioutil.WriteFile(dataFile1, []byte("ABC"), 0664)
+	c.Assert(err, IsNil)
+	dataFile2 := filepath.Join(s.tmpDir, "nogap2")
+	err = ioutil.WriteFile(dataFile2, []byte("DEFGH"), 0664)
+	c.Assert(err, IsNil)
+
+	file1, err := os.Open(dataFile1)
+	c.Assert(err, IsNil)
+	file2, err := os.Open(dataFile2)
+	c.Assert(err, IsNil)
+
+	mrseeker, err := New(file1, file2)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Size(), Equals, int64(8))
+
+	// Every byte can be read with Read
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFGH")
+
+	// ... and with Seek
+	for i := 0; i < 8; i++ {
+		pos, err := mrseeker.Seek(int64(i), WHENCE_START)
+		c.Assert(err, IsNil)
+		c.Check(pos, Equals, int64(i))
+		buf := make([]byte, 1)
+		n, err := mrseeker.Read(buf)
+		c.Assert(err, IsNil)
+		c.Assert(n, Equals, 1)
+		c.Check(buf[0], Equals, "ABCDEFGH"[i])
+	}
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestReadAcrossChildren(c *C) {
+	mrseeker, err := New(newSeekOnlyChild("ABC"), newSeekOnlyChild("DEFG"),
+		newSeekOnlyChild("HI"), newSeekOnlyChild("JKLMN"))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Size(), Equals, int64(14))
+
+	// A single Read fills the buffer from several children
+	buf := make([]byte, 10)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "ABCDEFGHIJ")
+
+	// Seek backwards into an earlier child
+	_, err = mrseeker.Seek(2, WHENCE_START)
+	c.Assert(err, IsNil)
+	n, err = mrseeker.Read(buf[:3])
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "CDE")
+
+	_, err = mrseeker.Seek(-1, WHENCE_END)
+	c.Assert(err, IsNil)
+	n, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "N")
+
+	// ReadAt exactly at a child boundary, spanning three children
+	_, err = mrseeker.Seek(1, WHENCE_START)
+	c.Assert(err, IsNil)
+	n, err = mrseeker.ReadAt(buf[:8], 3)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "DEFGHIJK")
+	n, err = mrseeker.Read(buf[:4])
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "BCDE")
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestEmptyChildren(c *C) {
+	// An empty child reads the same as no child at all
+	expected, err := New(newSeekOnlyChild("ABC"))
+	c.Assert(err, IsNil)
+	expectedData, err := ioutil.ReadAll(expected)
+	c.Assert(err, IsNil)
+
+	mrseeker, err := New(newSeekOnlyChild(""), newSeekOnlyChild("ABC"),
+		newSeekOnlyChild(""))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Size(), Equals, int64(3))
+	c.Check(mrseeker.children, HasLen, 1)
+	c.Check(mrseeker.superPosStart, DeepEquals, []int64{0})
+	c.Check(mrseeker.superPosEnd, DeepEquals, []int64{3})
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(data, DeepEquals, expectedData)
+
+	// Only empty children
+	mrseeker, err = New(newSeekOnlyChild(""), newSeekOnlyChild(""))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Size(), Equals, int64(0))
+	buf := make([]byte, 1)
+	n, err := mrseeker.Read(buf)
+	c.Check(n, Equals, 0)
+	c.Check(err, Equals, io.EOF)
+	pos, err := mrseeker.Seek(0, WHENCE_END)
+	c.Check(err, IsNil)
+	c.Check(pos, Equals, int64(0))
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestInitializeErrors(c *C) {
+	mrseeker, err := New()
+	c.Check(mrseeker, IsNil)
+	c.Check(errors.Is(err, ErrNoChildren), Equals, true)
+
+	mrseeker, err = New(newSeekOnlyChild("ABC"))
+	c.Assert(err, IsNil)
+	err = mrseeker.Initialize(newSeekOnlyChild("DEF"))
+	c.Check(errors.Is(err, ErrAlreadyInitialized), Equals, true)
+
+	// The failed Initialize changed nothing
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABC")
+}
+
+func (s *MySuite) TestCloseTwice(c *C) {
+	child1 := newSeekOnlyChild("ABC")
+	child2 := newSeekOnlyChild("")
+	mrseeker, err := New(child1, child2)
+	c.Assert(err, IsNil)
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+	c.Check(child1.closeCalls, Equals, 1)
+	c.Check(child2.closeCalls, Equals, 1)
+
+	err = mrseeker.Close()
+	c.Check(errors.Is(err, ErrAlreadyClosed), Equals, true)
+	c.Check(child1.closeCalls, Equals, 1)
+	c.Check(child2.closeCalls, Equals, 1)
+}
+
+func (s *MySuite) TestCloseErrors(c *C) {
+	children := []*scriptedChild{
+		newScriptedChild("ABC"),
+		newScriptedChild("DEF"),
+		newScriptedChild("GHI"),
+		newScriptedChild("JKL"),
+	}
+	children[1].closeErr = errors.New("close failed")
+	children[3].closeErr = errors.New("close failed")
+	mrseeker, err := New(children[0], children[1], children[2], children[3])
+	c.Assert(err, IsNil)
+
+	// Every child is closed, even though some fail
+	err = mrseeker.Close()
+	c.Assert(err, NotNil)
+	for _, child := range children {
+		c.Check(child.closed, Equals, true)
+	}
+	// In child order; how errset separates them is up to errset
+	c.Check(err, ErrorMatches,
+		"(?s)Closing io.Seeker #1 \\(0-based\\): close failed.+"+
+			"Closing io.Seeker #3 \\(0-based\\): close failed")
+}
+
+func (s *MySuite) TestUseAfterClose(c *C) {
+	mrseeker, err := NewFromStrings("ABC", "DEF")
+	c.Assert(err, IsNil)
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+
+	buf := make([]byte, 2)
+	_, err = mrseeker.Read(buf)
+	c.Check(errors.Is(err, ErrClosedSeeker), Equals, true)
+	_, err = mrseeker.ReadAt(buf, 1)
+	c.Check(errors.Is(err, ErrClosedSeeker), Equals, true)
+	_, err = mrseeker.Seek(1, io.SeekStart)
+	c.Check(errors.Is(err, ErrClosedSeeker), Equals, true)
+}
+
+func (s *MySuite) TestChildAt(c *C) {
+	child1 := newSeekOnlyChild("ABC")
+	child2 := newSeekOnlyChild("")
+	child3 := newSeekOnlyChild("DEF")
+	mrseeker, err := New(child1, child2, child3)
+	c.Assert(err, IsNil)
+
+	// The empty child isn't counted
+	c.Check(mrseeker.NumChildren(), Equals, 2)
+
+	child, err := mrseeker.ChildAt(0)
+	c.Assert(err, IsNil)
+	c.Check(child, Equals, child1)
+	child, err = mrseeker.ChildAt(1)
+	c.Assert(err, IsNil)
+	c.Check(child, Equals, child3)
+
+	_, err = mrseeker.ChildAt(2)
+	c.Check(errors.Is(err, ErrIndexOutOfRange), Equals, true)
+	_, err = mrseeker.ChildAt(-1)
+	c.Check(errors.Is(err, ErrIndexOutOfRange), Equals, true)
+}
+
+func (s *MySuite) TestCurrentChild(c *C) {
+	mrseeker, err := New(newSeekOnlyChild("ABC"), newSeekOnlyChild("DEFG"))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.CurrentChildIndex(), Equals, 0)
+	c.Check(mrseeker.CurrentChildOffset(), Equals, int64(0))
+
+	buf := make([]byte, 5)
+	_, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.CurrentChildIndex(), Equals, 1)
+	c.Check(mrseeker.CurrentChildOffset(), Equals, int64(2))
+
+	_, err = mrseeker.Seek(1, WHENCE_START)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.CurrentChildIndex(), Equals, 0)
+	c.Check(mrseeker.CurrentChildOffset(), Equals, int64(1))
+}
+
+func (s *MySuite) TestChildPositions(c *C) {
+	mrseeker, err := New(newSeekOnlyChild("ABC"), newSeekOnlyChild("DEFG"),
+		newSeekOnlyChild("HI"))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.ChildSizes(), DeepEquals, []int64{3, 4, 2})
+
+	size, err := mrseeker.ChildSizeAt(1)
+	c.Assert(err, IsNil)
+	c.Check(size, Equals, int64(4))
+	start, err := mrseeker.ChildStartPos(1)
+	c.Assert(err, IsNil)
+	c.Check(start, Equals, int64(3))
+	end, err := mrseeker.ChildEndPos(1)
+	c.Assert(err, IsNil)
+	c.Check(end, Equals, int64(7))
+
+	_, err = mrseeker.ChildSizeAt(3)
+	c.Check(errors.Is(err, ErrIndexOutOfRange), Equals, true)
+	_, err = mrseeker.ChildStartPos(-1)
+	c.Check(errors.Is(err, ErrIndexOutOfRange), Equals, true)
+	_, err = mrseeker.ChildEndPos(3)
+	c.Check(errors.Is(err, ErrIndexOutOfRange), Equals, true)
+}
+
+func (s *MySuite) TestPositionToChild(c *C) {
+	mrseeker, err := New(newSeekOnlyChild("ABC"), newSeekOnlyChild("DEFG"))
+	c.Assert(err, IsNil)
+
+	expected := []struct {
+		index  int
+		offset int64
+	}{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}, {1, 3}}
+	for pos, e := range expected {
+		index, offset, err := mrseeker.PositionToChild(int64(pos))
+		c.Assert(err, IsNil)
+		c.Check(index, Equals, e.index)
+		c.Check(offset, Equals, e.offset)
+	}
+
+	_, _, err = mrseeker.PositionToChild(-1)
+	c.Check(errors.Is(err, ErrNegativeSeek), Equals, true)
+	_, _, err = mrseeker.PositionToChild(7)
+	c.Check(errors.Is(err, ErrSeekPastEnd), Equals, true)
+}
+
+func (s *MySuite) TestReset(c *C) {
+	mrseeker, err := New(newSeekOnlyChild("ABC"), newSeekOnlyChild("DEFG"))
+	c.Assert(err, IsNil)
+
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFG")
+
+	err = mrseeker.Reset()
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Tell(), Equals, int64(0))
+	c.Check(mrseeker.CurrentChildIndex(), Equals, 0)
+
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFG")
+
+	// The rune for UnreadRune is forgotten
+	_, err = mrseeker.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	_, _, err = mrseeker.ReadRune()
+	c.Assert(err, IsNil)
+	err = mrseeker.Reset()
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.UnreadRune(), Equals, bufio.ErrInvalidUnreadRune)
+
+	// Not after Close
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+	err = mrseeker.Reset()
+	c.Check(err, Equals, ErrClosedSeeker)
+}
+
+func (s *MySuite) TestResetCloseOnEOF(c *C) {
+	// Lazy children that WithCloseOnEOF closed are opened again
+	var opened []*seekOnlyChild
+	open := func(data string) func() (ReadCloseSeeker, error) {
+		return func() (ReadCloseSeeker, error) {
+			child := newSeekOnlyChild(data)
+			opened = append(opened, child)
+			return child, nil
+		}
+	}
+	mrseeker, err := NewLazyWithOptions([]Option{WithCloseOnEOF(true)},
+		[]LazyChild{
+			{Size: 3, Open: open("ABC")},
+			{Size: 3, Open: open("DEF")},
+		})
+	c.Assert(err, IsNil)
+	data, err := mrseeker.ReadAll()
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEF")
+	c.Assert(opened, HasLen, 2)
+	c.Check(opened[0].closeCalls, Equals, 1)
+
+	err = mrseeker.Reset()
+	c.Assert(err, IsNil)
+	c.Check(opened, HasLen, 3)
+	data, err = mrseeker.ReadAll()
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEF")
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
 
-        // Close
+	// Other children can't be
+	mrseeker, err = NewWithOptions([]Option{WithCloseOnEOF(true)},
+		newSeekOnlyChild("ABC"), newSeekOnlyChild("DEF"))
+	c.Assert(err, IsNil)
+	data, err = mrseeker.ReadAll()
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEF")
+	err = mrseeker.Reset()
+	c.Check(errors.Is(err, ErrChildClosed), Equals, true)
 	err = mrseeker.Close()
 	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestWhenceConstants(c *C) {
+	whences := [][2]int{
+		{WHENCE_START, io.SeekStart},
+		{WHENCE_CURRENT, io.SeekCurrent},
+		{WHENCE_END, io.SeekEnd},
+	}
+	for _, w := range whences {
+		oldSeeker, err := New(newSeekOnlyChild("ABC"), newSeekOnlyChild("DEFG"))
+		c.Assert(err, IsNil)
+		newSeeker, err := New(newSeekOnlyChild("ABC"), newSeekOnlyChild("DEFG"))
+		c.Assert(err, IsNil)
+
+		for _, offset := range []int64{-4, -1, 0, 2, 5} {
+			oldPos, oldErr := oldSeeker.Seek(offset, w[0])
+			newPos, newErr := newSeeker.Seek(offset, w[1])
+			c.Check(oldPos, Equals, newPos)
+			c.Check(oldErr == nil, Equals, newErr == nil)
+
+			oldData, err := ioutil.ReadAll(oldSeeker)
+			c.Assert(err, IsNil)
+			newData, err := ioutil.ReadAll(newSeeker)
+			c.Assert(err, IsNil)
+			c.Check(oldData, DeepEquals, newData)
+		}
+	}
+}
+
+func (s *MySuite) TestSeekPastEnd(c *C) {
+	mrseeker, err := New(newSeekOnlyChild("ABC"), newSeekOnlyChild("DEFG"))
+	c.Assert(err, IsNil)
+	_, err = mrseeker.Seek(2, io.SeekStart)
+	c.Assert(err, IsNil)
+
+	pos, err := mrseeker.Seek(1, io.SeekEnd)
+	c.Check(errors.Is(err, ErrSeekPastEnd), Equals, true)
+	c.Check(err, ErrorMatches, ".*Seek\\(1, io.SeekEnd\\) with size 7.*")
+	// The position is unchanged
+	c.Check(pos, Equals, int64(2))
+	c.Check(mrseeker.Tell(), Equals, int64(2))
+
+	pos, err = mrseeker.Seek(0, io.SeekEnd)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(7))
+
+	pos, err = mrseeker.Seek(-8, io.SeekCurrent)
+	c.Check(errors.Is(err, ErrNegativeSeek), Equals, true)
+	c.Check(pos, Equals, int64(7))
+	_, err = mrseeker.ReadAt(make([]byte, 1), -1)
+	c.Check(errors.Is(err, ErrNegativeSeek), Equals, true)
+}
+
+// A ReadCloseSeeker whose Read results can be controlled, to exercise
+// the different results that the io.Reader contract allows.
+type scriptedChild struct {
+	data []byte
+	pos  int64
+	// The size reported by Seek(0, io.SeekEnd)
+	size int64
+	// Return io.EOF along with the last bytes, instead of on the next Read
+	eofWithData bool
+	// Return failErr along with the bytes that reach failAt
+	failAt  int64
+	failErr error
+	// Return failErr only the first time, then read on
+	failOnce bool
+	// Return seekErr from every Seek
+	seekErr error
+	// Return closeErr from Close
+	closeErr error
+	closed   bool
+}
 
+func newScriptedChild(data string) *scriptedChild {
+	return &scriptedChild{data: []byte(data), size: int64(len(data)), failAt: -1}
+}
+
+func (self *scriptedChild) Read(p []byte) (int, error) {
+	if self.pos >= int64(len(self.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, self.data[self.pos:])
+	if self.failAt >= 0 && self.pos+int64(n) >= self.failAt {
+		n = int(self.failAt - self.pos)
+		self.pos += int64(n)
+		if self.failOnce {
+			self.failAt = -1
+		}
+		return n, self.failErr
+	}
+	self.pos += int64(n)
+	if self.eofWithData && self.pos == int64(len(self.data)) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (self *scriptedChild) Seek(offset int64, whence int) (int64, error) {
+	if self.seekErr != nil {
+		return self.pos, self.seekErr
+	}
+	switch whence {
+	case io.SeekStart:
+		self.pos = offset
+	case io.SeekCurrent:
+		self.pos += offset
+	case io.SeekEnd:
+		self.pos = self.size + offset
+	}
+	return self.pos, nil
+}
+
+func (self *scriptedChild) Close() error {
+	self.closed = true
+	return self.closeErr
+}
+
+func (s *MySuite) TestSeekError(c *C) {
+	child2 := newScriptedChild("DEF")
+	mrseeker, err := New(newScriptedChild("ABC"), child2)
+	c.Assert(err, IsNil)
+
+	errBoom := errors.New("boom")
+	child2.seekErr = errBoom
+	_, err = mrseeker.Seek(4, io.SeekStart)
+	var seekErr *SeekError
+	c.Assert(errors.As(err, &seekErr), Equals, true)
+	c.Check(*seekErr, Equals, SeekError{
+		ChildIndex: 1,
+		Offset:     1,
+		Whence:     io.SeekStart,
+		Err:        errBoom,
+	})
+	c.Check(errors.Is(err, errBoom), Equals, true)
+	c.Check(errors.Cause(err), Equals, errBoom)
+	c.Check(err, ErrorMatches, "Seeking io.Seeker #1 \\(0-based\\) to 1: boom")
+	c.Check(mrseeker.Tell(), Equals, int64(0))
+}
+
+func (s *MySuite) TestChildReadResults(c *C) {
+	buf := make([]byte, 20)
+
+	// (n > 0, nil) and (n > 0, io.EOF)
+	child1 := newScriptedChild("ABC")
+	child1.eofWithData = true
+	child2 := newScriptedChild("DEF")
+	mrseeker, err := New(child1, child2)
+	c.Assert(err, IsNil)
+	n, err := mrseeker.Read(buf[:2])
+	c.Check(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "AB")
+	n, err = mrseeker.Read(buf)
+	c.Check(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "CDEF")
+	n, err = mrseeker.Read(buf)
+	c.Check(n, Equals, 0)
+	c.Check(err, Equals, io.EOF)
+
+	// (n > 0, some other error)
+	errBoom := errors.New("boom")
+	child1 = newScriptedChild("ABC")
+	child2 = newScriptedChild("DEFGH")
+	child2.failAt = 2
+	child2.failErr = errBoom
+	mrseeker, err = New(child1, child2)
+	c.Assert(err, IsNil)
+	n, err = mrseeker.Read(buf)
+	c.Check(errors.Is(err, errBoom), Equals, true)
+	c.Check(string(buf[:n]), Equals, "ABCDE")
+	c.Check(mrseeker.Tell(), Equals, int64(5))
+
+	// (0, io.EOF) before the end that we measured
+	child1 = newScriptedChild("ABC")
+	child1.size = 5
+	child2 = newScriptedChild("DEF")
+	mrseeker, err = New(child1, child2)
+	c.Assert(err, IsNil)
+	n, err = mrseeker.Read(buf)
+	c.Check(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "ABC")
+	n, err = mrseeker.Read(buf)
+	c.Check(n, Equals, 0)
+	c.Check(errors.Is(err, io.ErrUnexpectedEOF), Equals, true)
+}
+
+func (s *MySuite) TestNewLazy(c *C) {
+	opened := make([]int, 3)
+	lazyChild := func(i int, data string) LazyChild {
+		return LazyChild{
+			Size: int64(len(data)),
+			Open: func() (ReadCloseSeeker, error) {
+				opened[i]++
+				return newSeekOnlyChild(data), nil
+			},
+		}
+	}
+	mrseeker, err := NewLazy([]LazyChild{
+		lazyChild(0, "ABC"), lazyChild(1, "DEFG"), lazyChild(2, "HI"),
+	})
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Size(), Equals, int64(9))
+	c.Check(opened, DeepEquals, []int{0, 0, 0})
+
+	buf := make([]byte, 2)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "AB")
+	c.Check(opened, DeepEquals, []int{1, 0, 0})
+
+	// Seeking into a child opens it
+	_, err = mrseeker.Seek(8, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Check(opened, DeepEquals, []int{1, 0, 1})
+	n, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "I")
+
+	_, err = mrseeker.Seek(0, io.SeekStart)
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFGHI")
+	c.Check(opened, DeepEquals, []int{1, 1, 1})
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestNewLazyErrors(c *C) {
+	_, err := NewLazy(nil)
+	c.Check(errors.Is(err, ErrNoChildren), Equals, true)
+
+	_, err = NewLazy([]LazyChild{{Size: -1}})
+	c.Check(err, NotNil)
+
+	errOpen := errors.New("open failed")
+	mrseeker, err := NewLazy([]LazyChild{
+		{Size: 3, Open: func() (ReadCloseSeeker, error) {
+			return newSeekOnlyChild("ABC"), nil
+		}},
+		{Size: 3, Open: func() (ReadCloseSeeker, error) {
+			return nil, errOpen
+		}},
+	})
+	c.Assert(err, IsNil)
+	buf := make([]byte, 6)
+	n, err := mrseeker.Read(buf)
+	c.Check(string(buf[:n]), Equals, "ABC")
+	c.Check(errors.Is(err, errOpen), Equals, true)
+
+	// Close only closes the child that was opened
+	err = mrseeker.Close()
+	c.Check(err, IsNil)
+}
+
+func (s *MySuite) TestNewEmpty(c *C) {
+	mrseeker := NewEmpty()
+	c.Check(mrseeker.Size(), Equals, int64(0))
+	c.Check(mrseeker.NumChildren(), Equals, 0)
+
+	buf := make([]byte, 4)
+	n, err := mrseeker.Read(buf)
+	c.Check(n, Equals, 0)
+	c.Check(err, Equals, io.EOF)
+	n, err = mrseeker.ReadAt(buf, 0)
+	c.Check(n, Equals, 0)
+	c.Check(err, Equals, io.EOF)
+
+	pos, err := mrseeker.Seek(0, io.SeekEnd)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(0))
+	pos, err = mrseeker.Seek(5, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(5))
+	c.Check(mrseeker.CurrentChildOffset(), Equals, int64(5))
+
+	// It's already initialized
+	err = mrseeker.Initialize(newSeekOnlyChild("A"))
+	c.Check(err, Equals, ErrAlreadyInitialized)
+
+	err = mrseeker.Close()
+	c.Check(err, IsNil)
+}
+
+func (s *MySuite) TestNewWithOptions(c *C) {
+	mrseeker, err := NewWithOptions(nil, newSeekOnlyChild("ABC"), newSeekOnlyChild("DEF"))
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEF")
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+
+	_, err = NewWithOptions([]Option{WithFollowSymlinks()})
+	c.Check(err, Equals, ErrNoChildren)
+}
+
+func (s *MySuite) TestCloseOnEOF(c *C) {
+	children := []*seekOnlyChild{
+		newSeekOnlyChild("ABC"),
+		newSeekOnlyChild("DEF"),
+		newSeekOnlyChild("GHI"),
+	}
+	mrseeker, err := NewWithOptions([]Option{WithCloseOnEOF(true)},
+		children[0], children[1], children[2])
+	c.Assert(err, IsNil)
+
+	// The first child is closed when Read moves on to the second
+	buf := make([]byte, 3)
+	_, err = io.ReadFull(mrseeker, buf)
+	c.Assert(err, IsNil)
+	c.Check(children[0].closeCalls, Equals, 0)
+	_, err = io.ReadFull(mrseeker, buf[:1])
+	c.Assert(err, IsNil)
+	c.Check(children[0].closeCalls, Equals, 1)
+	c.Check(children[1].closeCalls, Equals, 0)
+
+	// It can't be read again
+	_, err = mrseeker.Seek(1, io.SeekStart)
+	c.Check(errors.Is(err, ErrChildClosed), Equals, true)
+	_, err = mrseeker.ReadAt(buf, 0)
+	c.Check(errors.Is(err, ErrChildClosed), Equals, true)
+
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "EFGHI")
+	c.Check(children[1].closeCalls, Equals, 1)
+	c.Check(children[2].closeCalls, Equals, 0)
+
+	// Close doesn't close the children that were already closed
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+	for _, child := range children {
+		c.Check(child.closeCalls, Equals, 1)
+	}
+}
+
+func (s *MySuite) TestCloseOnEOFLazy(c *C) {
+	var opened []*seekOnlyChild
+	open := func(data string) func() (ReadCloseSeeker, error) {
+		return func() (ReadCloseSeeker, error) {
+			child := newSeekOnlyChild(data)
+			opened = append(opened, child)
+			return child, nil
+		}
+	}
+	mrseeker, err := NewLazy([]LazyChild{
+		{Size: 3, Open: open("ABC")},
+		{Size: 3, Open: open("DEF")},
+	})
+	c.Assert(err, IsNil)
+	mrseeker.options.closeOnEOF = true
+
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEF")
+	c.Assert(opened, HasLen, 2)
+	c.Check(opened[0].closeCalls, Equals, 1)
+
+	// A lazy child is opened again
+	_, err = mrseeker.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Assert(opened, HasLen, 3)
+	buf := make([]byte, 2)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "BC")
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+	for _, child := range opened {
+		c.Check(child.closeCalls, Equals, 1)
+	}
+}
+
+func (s *MySuite) TestErrorHandler(c *C) {
+	errBad := errors.New("bad child")
+	newChildren := func() []ReadCloseSeeker {
+		bad := newScriptedChild("DEFG")
+		bad.failAt = 2
+		bad.failErr = errBad
+		return []ReadCloseSeeker{newScriptedChild("ABC"), bad, newScriptedChild("HIJ")}
+	}
+	var failedIdx []int
+	handler := func(skip bool) func(int, error) bool {
+		failedIdx = nil
+		return func(childIdx int, err error) bool {
+			c.Check(errors.Is(err, errBad), Equals, true)
+			failedIdx = append(failedIdx, childIdx)
+			return skip
+		}
+	}
+
+	// The rest of the bad child is left out
+	mrseeker, err := NewWithOptions([]Option{WithErrorHandler(handler(true))},
+		newChildren()...)
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEHIJ")
+	c.Check(failedIdx, DeepEquals, []int{1})
+
+	// The bad child isn't read again
+	_, err = mrseeker.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "BCHIJ")
+	c.Check(failedIdx, DeepEquals, []int{1})
+
+	// Or it's filled with zeros
+	mrseeker, err = NewWithOptions(
+		[]Option{WithErrorHandler(handler(true)), WithZeroFill()},
+		newChildren()...)
+	c.Assert(err, IsNil)
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDE\x00\x00HIJ")
+	c.Check(failedIdx, DeepEquals, []int{1})
+	_, err = mrseeker.Seek(5, io.SeekStart)
+	c.Assert(err, IsNil)
+	buf := make([]byte, 3)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "\x00\x00H")
+
+	// The handler can let the error through
+	mrseeker, err = NewWithOptions([]Option{WithErrorHandler(handler(false))},
+		newChildren()...)
+	c.Assert(err, IsNil)
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Check(errors.Is(err, errBad), Equals, true)
+	c.Check(string(data), Equals, "ABCDE")
+	c.Check(failedIdx, DeepEquals, []int{1})
+}
+
+func (s *MySuite) TestErrorHandlerLazyOpen(c *C) {
+	errOpen := errors.New("open failed")
+	mrseeker, err := NewLazy([]LazyChild{
+		{Size: 3, Open: func() (ReadCloseSeeker, error) {
+			return newSeekOnlyChild("ABC"), nil
+		}},
+		{Size: 3, Open: func() (ReadCloseSeeker, error) {
+			return nil, errOpen
+		}},
+		{Size: 3, Open: func() (ReadCloseSeeker, error) {
+			return newSeekOnlyChild("GHI"), nil
+		}},
+	})
+	c.Assert(err, IsNil)
+	mrseeker.options.errorHandler = func(childIdx int, err error) bool {
+		c.Check(childIdx, Equals, 1)
+		c.Check(errors.Is(err, errOpen), Equals, true)
+		return true
+	}
+
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCGHI")
+}
+
+// A child that cancels a context when it is read
+type cancelingChild struct {
+	*seekOnlyChild
+	cancel context.CancelFunc
+}
+
+func (self cancelingChild) Read(p []byte) (int, error) {
+	self.cancel()
+	return self.seekOnlyChild.Read(p)
+}
+
+func (s *MySuite) TestContext(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mrseeker, err := NewWithOptions([]Option{WithContext(ctx)},
+		newSeekOnlyChild("ABC"),
+		cancelingChild{newSeekOnlyChild("DEF"), cancel},
+		newSeekOnlyChild("GHI"))
+	c.Assert(err, IsNil)
+
+	// The context is cancelled while reading the second child; Read
+	// stops before it moves on to the third
+	buf := make([]byte, 9)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "ABCDEF")
+	n, err = mrseeker.Read(buf)
+	c.Check(n, Equals, 0)
+	c.Check(err, Equals, context.Canceled)
+	c.Check(mrseeker.Tell(), Equals, int64(6))
+
+	// Seek still works
+	pos, err := mrseeker.Seek(-2, io.SeekCurrent)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(4))
+	_, err = mrseeker.Read(buf)
+	c.Check(err, Equals, context.Canceled)
+
+	// And so does ReadAt, which doesn't use the context
+	n, err = mrseeker.ReadAt(buf[:3], 4)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "EFG")
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+}
+
+// Seek around a MultiReadSeeker with many children
+func (s *MySuite) BenchmarkSeekManyChildren(c *C) {
+	slices := make([][]byte, 10000)
+	for i := range slices {
+		slices[i] = make([]byte, 100)
+	}
+	mrseeker, err := NewFromBytes(slices...)
+	c.Assert(err, IsNil)
+	buf := make([]byte, 10)
+	c.ResetTimer()
+	for i := 0; i < c.N; i++ {
+		_, err = mrseeker.Seek(int64(i*7919)%(mrseeker.Size()-10), io.SeekStart)
+		if err != nil {
+			c.Fatal(err)
+		}
+		_, err = mrseeker.Read(buf)
+		if err != nil {
+			c.Fatal(err)
+		}
+	}
 }
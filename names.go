@@ -0,0 +1,70 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Names for the children, as for progress messages, or to find a
+// child without knowing its index.
+
+import (
+	"github.com/pkg/errors"
+)
+
+// A NamedChild is a child and its name, for NewFromNamedChildren.
+type NamedChild struct {
+	Name   string
+	Reader ReadCloseSeeker
+}
+
+// NewFromNamedChildren is like New, but each child has a name. As with
+// New, empty children are skipped, and so are their names.
+func NewFromNamedChildren(children ...NamedChild) (*MultiReadSeeker, error) {
+	if len(children) == 0 {
+		return nil, ErrNoChildren
+	}
+	sizes := make([]int64, len(children))
+	for i, child := range children {
+		var err error
+		sizes[i], err = measureChild(child.Reader)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	mrseeker := NewEmpty()
+	for i, child := range children {
+		if sizes[i] == 0 {
+			mrseeker.emptyChildren = append(mrseeker.emptyChildren, child.Reader)
+			continue
+		}
+		mrseeker.appendChild(child.Reader, sizes[i], nil)
+		mrseeker.names[len(mrseeker.names)-1] = child.Name
+	}
+	return mrseeker, nil
+}
+
+// ChildNames returns the names of the children, in order. A child
+// without a name has "".
+func (self *MultiReadSeeker) ChildNames() []string {
+	return append([]string(nil), self.names...)
+}
+
+// ChildByName returns the index of the first child named name, and the
+// child. If no child has that name, the error is ErrChildNotFound.
+func (self *MultiReadSeeker) ChildByName(name string) (int, ReadCloseSeeker, error) {
+	for i, childName := range self.names {
+		if childName == name {
+			child, err := self.child(i)
+			return i, child, err
+		}
+	}
+	return 0, nil, errors.Wrapf(ErrChildNotFound, "No child named %q", name)
+}
+
+// SetChildName sets the name of the child at index i.
+func (self *MultiReadSeeker) SetChildName(i int, name string) error {
+	err := self.checkChildIndex(i)
+	if err != nil {
+		return err
+	}
+	self.names[i] = name
+	return nil
+}
@@ -0,0 +1,61 @@
+package multireadseeker
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestNamedChildren(c *C) {
+	mrseeker, err := NewFromNamedChildren(
+		NamedChild{"segment-1.log", StringChild("ABC")},
+		NamedChild{"empty.log", StringChild("")},
+		NamedChild{"segment-2.log", StringChild("DEF")},
+	)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.ChildNames(), DeepEquals,
+		[]string{"segment-1.log", "segment-2.log"})
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEF")
+
+	i, child, err := mrseeker.ChildByName("segment-2.log")
+	c.Assert(err, IsNil)
+	c.Check(i, Equals, 1)
+	_, err = child.Seek(0, io.SeekStart)
+	c.Assert(err, IsNil)
+	data, err = ioutil.ReadAll(child)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "DEF")
+	_, _, err = mrseeker.ChildByName("empty.log")
+	c.Check(errors.Is(err, ErrChildNotFound), Equals, true)
+
+	// Names move with their children
+	err = mrseeker.Insert(1, StringChild("XYZ"))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.ChildNames(), DeepEquals,
+		[]string{"segment-1.log", "", "segment-2.log"})
+	err = mrseeker.SetChildName(1, "inserted")
+	c.Assert(err, IsNil)
+	err = mrseeker.Swap(0, 2)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.ChildNames(), DeepEquals,
+		[]string{"segment-2.log", "inserted", "segment-1.log"})
+	err = mrseeker.Remove(0)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.ChildNames(), DeepEquals,
+		[]string{"inserted", "segment-1.log"})
+
+	clone, err := mrseeker.Clone()
+	c.Assert(err, IsNil)
+	c.Check(clone.ChildNames(), DeepEquals, mrseeker.ChildNames())
+
+	err = mrseeker.SetChildName(2, "none")
+	c.Check(errors.Is(err, ErrIndexOutOfRange), Equals, true)
+	c.Assert(mrseeker.Close(), IsNil)
+
+	_, err = NewFromNamedChildren()
+	c.Check(errors.Is(err, ErrNoChildren), Equals, true)
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Observers, for metrics and tracing in other packages.
+
+// An Observer is told what a MultiReadSeeker does; see WithObserver.
+// Its methods are called by Read and Seek, in their goroutine, so they
+// must return quickly. They may call the MultiReadSeeker's methods
+// that only report on it, such as Tell, CurrentChildIndex, and
+// ChildNames, but not Read or Seek.
+type Observer interface {
+	// StartRead is called when Read starts, with len(p). The function
+	// it returns, if not nil, is called when Read returns, with what
+	// it returns.
+	StartRead(mrseeker *MultiReadSeeker, requested int) func(n int, err error)
+
+	// Seeked is called when Seek returns, with what it returns.
+	Seeked(mrseeker *MultiReadSeeker, pos int64, err error)
+
+	// ChildSwitched is called when Read moves from the end of child
+	// fromIndex to the start of child toIndex, as for OnChildSwitch.
+	ChildSwitched(mrseeker *MultiReadSeeker, fromIndex, toIndex int)
+}
+
+// Tell the observers that Read has started, and return the function to
+// tell them that it has returned
+func (self *MultiReadSeeker) startRead(requested int) func(n int, err error) {
+	var finishes []func(n int, err error)
+	for _, observer := range self.options.observers {
+		if finish := observer.StartRead(self, requested); finish != nil {
+			finishes = append(finishes, finish)
+		}
+	}
+	return func(n int, err error) {
+		for _, finish := range finishes {
+			finish(n, err)
+		}
+	}
+}
+
+func (self *MultiReadSeeker) observeSeek(pos int64, err error) {
+	for _, observer := range self.options.observers {
+		observer.Seeked(self, pos, err)
+	}
+}
+
+func (self *MultiReadSeeker) observeChildSwitch(fromIndex, toIndex int) {
+	for _, observer := range self.options.observers {
+		observer.ChildSwitched(self, fromIndex, toIndex)
+	}
+}
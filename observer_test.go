@@ -0,0 +1,54 @@
+package multireadseeker
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	. "gopkg.in/check.v1"
+)
+
+// Records what it is told, as strings
+type recordingObserver struct {
+	events []string
+}
+
+func (self *recordingObserver) StartRead(mrseeker *MultiReadSeeker, requested int) func(n int, err error) {
+	self.events = append(self.events, fmt.Sprintf("read %d at %d", requested, mrseeker.Tell()))
+	return func(n int, err error) {
+		self.events = append(self.events, fmt.Sprintf("read returned %d, %v", n, err))
+	}
+}
+
+func (self *recordingObserver) Seeked(mrseeker *MultiReadSeeker, pos int64, err error) {
+	self.events = append(self.events, fmt.Sprintf("seeked to %d, %v", pos, err))
+}
+
+func (self *recordingObserver) ChildSwitched(mrseeker *MultiReadSeeker, fromIndex, toIndex int) {
+	self.events = append(self.events, fmt.Sprintf("switched %d to %d", fromIndex, toIndex))
+}
+
+func (s *MySuite) TestObserver(c *C) {
+	observer := &recordingObserver{}
+	mrseeker, err := NewWithOptions([]Option{WithObserver(observer)},
+		StringChild("ABC"), StringChild("DEF"))
+	c.Assert(err, IsNil)
+
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(mrseeker, buf)
+	c.Assert(err, IsNil)
+	_, err = mrseeker.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	_, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(observer.events[:7], DeepEquals, []string{
+		"read 4 at 0",
+		"switched 0 to 1",
+		"read returned 4, <nil>",
+		"seeked to 1, <nil>",
+		"read 512 at 1",
+		"switched 0 to 1",
+		"read returned 5, <nil>",
+	})
+	c.Check(observer.events[len(observer.events)-1], Equals, "read returned 0, EOF")
+}
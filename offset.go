@@ -0,0 +1,110 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Reading a byte range of a child, as for Section and Split.
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// A child that is only the bytes in [start, end) of another child.
+// Positions are relative to start.
+type offsetChild struct {
+	child      ReadCloseSeeker
+	start, end int64
+	pos        int64
+}
+
+// An offsetChild for a child that has ReadAt, so that it has ReadAt too
+type offsetReaderAtChild struct {
+	*offsetChild
+	readerAt io.ReaderAt
+}
+
+// OffsetChild returns a child that is only the bytes in [start, end)
+// of r, like io.SectionReader, but which can be closed. Its positions
+// are relative to start, so Seek(0, io.SeekStart) seeks r to start; it
+// reads from wherever r is until it is first seeked, as New does to
+// find its size. It has a Size method, which returns end - start. If
+// end is less than start, it is empty. Close closes r.
+func OffsetChild(r ReadCloseSeeker, start, end int64) ReadCloseSeeker {
+	if end < start {
+		end = start
+	}
+	offset := &offsetChild{
+		child: r,
+		start: start,
+		end:   end,
+	}
+	if readerAt, ok := r.(io.ReaderAt); ok {
+		return offsetReaderAtChild{offset, readerAt}
+	}
+	return offset
+}
+
+// Size returns the number of bytes in the range.
+func (self *offsetChild) Size() int64 {
+	return self.end - self.start
+}
+
+func (self *offsetChild) Read(p []byte) (int, error) {
+	remaining := self.Size() - self.pos
+	if remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := self.child.Read(p)
+	self.pos += int64(n)
+	return n, err
+}
+
+func (self *offsetChild) Seek(offset int64, whence int) (int64, error) {
+	var newPos int64
+	switch whence {
+	case io.SeekStart:
+		newPos = offset
+	case io.SeekCurrent:
+		newPos = self.pos + offset
+	case io.SeekEnd:
+		newPos = self.Size() + offset
+	default:
+		return self.pos, errors.Errorf(
+			"Seek(offset, whence); whence must be 0, 1, or 2, not %d", whence)
+	}
+	if newPos < 0 {
+		return self.pos, errors.Wrapf(ErrNegativeSeek, "Seek to %d", newPos)
+	}
+	_, err := self.child.Seek(self.start+newPos, io.SeekStart)
+	if err != nil {
+		return self.pos, err
+	}
+	self.pos = newPos
+	return self.pos, nil
+}
+
+func (self *offsetChild) Close() error {
+	return self.child.Close()
+}
+
+func (self offsetReaderAtChild) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.Wrapf(ErrNegativeSeek, "ReadAt offset %d", off)
+	}
+	remaining := self.Size() - off
+	if remaining <= 0 {
+		return 0, io.EOF
+	}
+	short := int64(len(p)) > remaining
+	if short {
+		p = p[:remaining]
+	}
+	n, err := self.readerAt.ReadAt(p, self.start+off)
+	if err == nil && short {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,37 @@
+package multireadseeker
+
+import (
+	"io"
+	"io/ioutil"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestOffsetChild(c *C) {
+	child := OffsetChild(StringChild("0123456789"), 3, 8)
+	c.Check(child.(interface{ Size() int64 }).Size(), Equals, int64(5))
+
+	mrseeker, err := New(StringChild("<"), child, StringChild(">"))
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "<34567>")
+
+	pos, err := child.Seek(-2, io.SeekEnd)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(3))
+	buf := make([]byte, 4)
+	n, err := child.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "67")
+	_, err = child.Read(buf)
+	c.Check(err, Equals, io.EOF)
+	n, err = child.(io.ReaderAt).ReadAt(buf, 2)
+	c.Check(err, Equals, io.EOF)
+	c.Check(string(buf[:n]), Equals, "567")
+
+	empty := OffsetChild(StringChild("0123456789"), 5, 2)
+	size, err := empty.Seek(0, io.SeekEnd)
+	c.Assert(err, IsNil)
+	c.Check(size, Equals, int64(0))
+}
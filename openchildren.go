@@ -0,0 +1,112 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Keeping only some of the children open, for WithMaxOpenFiles.
+
+import (
+	"container/list"
+)
+
+// The children that are open and can be opened again, by index, with
+// the one used most recently at the front, so that when more than max
+// are open, the ones used least recently can be closed.
+type openChildren struct {
+	max int
+
+	// The values are child indexes
+	order *list.List
+	elems map[int]*list.Element
+}
+
+func newOpenChildren(max int) *openChildren {
+	return &openChildren{
+		max:   max,
+		order: list.New(),
+		elems: make(map[int]*list.Element),
+	}
+}
+
+// Note that child i was just used. A nil *openChildren keeps track of
+// nothing.
+func (self *openChildren) use(i int) {
+	if self == nil {
+		return
+	}
+	if elem, ok := self.elems[i]; ok {
+		self.order.MoveToFront(elem)
+		return
+	}
+	self.elems[i] = self.order.PushFront(i)
+}
+
+// Forget child i, which has been closed
+func (self *openChildren) remove(i int) {
+	if self == nil {
+		return
+	}
+	if elem, ok := self.elems[i]; ok {
+		self.order.Remove(elem)
+		delete(self.elems, i)
+	}
+}
+
+// Forget all the children, as when their indexes have changed
+func (self *openChildren) reset() {
+	if self == nil {
+		return
+	}
+	self.order.Init()
+	self.elems = make(map[int]*list.Element)
+}
+
+// The number of children that are open
+func (self *openChildren) len() int {
+	if self == nil {
+		return 0
+	}
+	return self.order.Len()
+}
+
+// Note that the child at index i, which is open, was just used, and,
+// with WithMaxOpenFiles, close the children that were used least
+// recently if too many are open. Only children that can be opened
+// again, from NewLazy or WithChildFactory, are counted and closed, and
+// neither child i nor the current child is closed. While the children
+// are shared, as with Clone, none are closed, since the others may be
+// reading them.
+func (self *MultiReadSeeker) usedChild(i int) {
+	if self.options.maxOpenFiles < 1 || self.openers[i] == nil {
+		return
+	}
+	if self.openChildren == nil {
+		self.openChildren = newOpenChildren(self.options.maxOpenFiles)
+	}
+	self.openChildren.use(i)
+	if self.shared != nil {
+		return
+	}
+	elem := self.openChildren.order.Back()
+	for elem != nil && self.openChildren.len() > self.openChildren.max {
+		prev := elem.Prev()
+		j := elem.Value.(int)
+		if j != i && j != self.currentSeekerNum {
+			// Nothing is reading it, so an error doesn't matter
+			self.closeChild(j) // ignore any error
+		}
+		elem = prev
+	}
+}
+
+// Count the children that are open again, after their indexes have
+// changed. Which were used most recently is lost.
+func (self *MultiReadSeeker) recountOpenChildren() {
+	if self.openChildren == nil {
+		return
+	}
+	self.openChildren.reset()
+	for i, child := range self.children {
+		if child != nil && self.openers[i] != nil {
+			self.openChildren.use(i)
+		}
+	}
+}
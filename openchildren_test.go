@@ -0,0 +1,136 @@
+package multireadseeker
+
+import (
+	"io"
+
+	. "gopkg.in/check.v1"
+)
+
+// Children that keep track of how many of them are open
+type openCounter struct {
+	data     []string
+	children []*seekOnlyChild
+}
+
+func (self *openCounter) open(i int) (ReadCloseSeeker, error) {
+	child := newSeekOnlyChild(self.data[i])
+	self.children = append(self.children, child)
+	return child, nil
+}
+
+func (self *openCounter) numOpen() int {
+	n := 0
+	for _, child := range self.children {
+		if child.closeCalls == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *MySuite) TestMaxOpenFilesChildFactory(c *C) {
+	counter := &openCounter{data: []string{"ABC", "DEF", "GHI", "JKL", "MNO"}}
+	children := make([]ReadCloseSeeker, len(counter.data))
+	for i := range children {
+		children[i], _ = counter.open(i)
+	}
+	mrseeker, err := NewWithOptions(
+		[]Option{WithMaxOpenFiles(2), WithChildFactory(counter.open)},
+		children...)
+	c.Assert(err, IsNil)
+
+	// The current child, and the one added last
+	c.Check(counter.numOpen(), Equals, 2)
+
+	var data []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := mrseeker.Read(buf)
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, IsNil)
+		data = append(data, buf[:n]...)
+		c.Assert(counter.numOpen() <= 2, Equals, true)
+	}
+	c.Check(string(data), Equals, "ABCDEFGHIJKLMNO")
+
+	// Children that were closed are opened again
+	buf = make([]byte, 4)
+	n, err := mrseeker.ReadAt(buf, 1)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "BCDE")
+	c.Check(counter.numOpen(), Equals, 2)
+
+	_, err = mrseeker.Seek(7, io.SeekStart)
+	c.Assert(err, IsNil)
+	n, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "HIJK")
+	c.Check(counter.numOpen(), Equals, 2)
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+	c.Check(counter.numOpen(), Equals, 0)
+}
+
+func (s *MySuite) TestMaxOpenFilesLazy(c *C) {
+	counter := &openCounter{data: []string{"ABC", "DEF", "GHI", "JKL"}}
+	lazyChildren := make([]LazyChild, len(counter.data))
+	for i := range lazyChildren {
+		i := i
+		lazyChildren[i] = LazyChild{
+			Size: 3,
+			Open: func() (ReadCloseSeeker, error) { return counter.open(i) },
+		}
+	}
+	mrseeker, err := NewLazyWithOptions([]Option{WithMaxOpenFiles(1)}, lazyChildren)
+	c.Assert(err, IsNil)
+
+	data, err := mrseeker.ReadAll()
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFGHIJKL")
+	c.Check(counter.children, HasLen, 4)
+	c.Check(counter.numOpen(), Equals, 1)
+
+	_, err = mrseeker.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Check(counter.numOpen(), Equals, 1)
+	buf := make([]byte, 4)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "BCDE")
+	c.Check(counter.numOpen(), Equals, 1)
+
+	// Nothing is closed while a clone may be reading it
+	clone, err := mrseeker.Clone()
+	c.Assert(err, IsNil)
+	c.Check(counter.numOpen(), Equals, 4)
+	data, err = clone.ReadAll()
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFGHIJKL")
+	data, err = mrseeker.ReadAll()
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "FGHIJKL")
+	c.Check(counter.numOpen(), Equals, 4)
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+	c.Check(counter.numOpen(), Equals, 0)
+}
+
+func (s *MySuite) TestMaxOpenFilesWithoutFactory(c *C) {
+	// Children that can't be opened again are left open
+	counter := &openCounter{data: []string{"ABC", "DEF", "GHI"}}
+	children := make([]ReadCloseSeeker, len(counter.data))
+	for i := range children {
+		children[i], _ = counter.open(i)
+	}
+	mrseeker, err := NewWithOptions([]Option{WithMaxOpenFiles(1)}, children...)
+	c.Assert(err, IsNil)
+	data, err := mrseeker.ReadAll()
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFGHI")
+	c.Check(counter.numOpen(), Equals, 3)
+	c.Assert(mrseeker.Close(), IsNil)
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+import (
+	"context"
+)
+
+// An Option changes how a MultiReadSeeker, or one of its constructors,
+// behaves. Options that don't apply to a constructor are ignored.
+type Option func(*options)
+
+type options struct {
+	// NewFromDirectory includes symlinks to regular files
+	followSymlinks bool
+
+	// NewFromReaders keeps readers up to this size in memory
+	maxMemory int64
+
+	// If > 0, each child's reads go through a buffer this big
+	bufferSize int
+
+	// Read closes each child when it moves on to the next one
+	closeOnEOF bool
+
+	// Decides whether Read and Seek skip a child that fails
+	errorHandler func(childIdx int, err error) bool
+
+	// Skipped children read as zeros, instead of being left out
+	zeroFill bool
+
+	// If not nil, Read stops when this is done
+	ctx context.Context
+
+	// Open the next child from NewLazy in the background when there
+	// are fewer than readAheadThreshold bytes left in the current one
+	readAhead          bool
+	readAheadThreshold int64
+
+	// The name that Stat gives
+	name string
+
+	// MapChildren calls its function when a child is first read
+	lazyMap bool
+
+	// ConcatFile keeps up to this many files open, and a
+	// MultiReadSeeker up to this many children that it can open again
+	maxOpenFiles int
+
+	// Opens a child given to New or Append again after it was closed
+	childFactory func(i int) (ReadCloseSeeker, error)
+
+	// What happens to a child whose size is 0
+	emptyChildPolicy EmptyChildPolicy
+
+	// Told about each Read, Seek, and child switch
+	observers []Observer
+}
+
+// By default, WithReadAhead starts opening the next child when there
+// are fewer than 64 KiB left to read in the current one
+const DefaultReadAheadThreshold = 64 << 10
+
+// By default, NewFromReaders keeps readers of up to 32 MiB in memory
+const DefaultMaxMemory = 32 << 20
+
+// Without WithName, this is the name that Stat gives
+const DefaultName = "multireadseeker"
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		maxMemory:          DefaultMaxMemory,
+		readAheadThreshold: DefaultReadAheadThreshold,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithFollowSymlinks makes NewFromDirectory include symlinks that
+// point to regular files. Without it, symlinks are skipped.
+func WithFollowSymlinks() Option {
+	return func(o *options) {
+		o.followSymlinks = true
+	}
+}
+
+// WithMaxMemory sets the largest reader that NewFromReadersWithOptions
+// buffers in memory; larger readers are buffered in temporary files.
+// The default is DefaultMaxMemory.
+func WithMaxMemory(n int64) Option {
+	return func(o *options) {
+		o.maxMemory = n
+	}
+}
+
+// WithBufferSize makes reads from each child go through a buffer of n
+// bytes, so that many small Reads become fewer, larger reads of the
+// child. This helps when each read of a child is slow, as on network
+// storage. The default, 0, is no buffering.
+func WithBufferSize(n int) Option {
+	return func(o *options) {
+		o.bufferSize = n
+	}
+}
+
+// WithCloseOnEOF makes Read close each child as soon as it has read all
+// of it and moves on to the next child, rather than leaving them all
+// for Close. This keeps fewer files open when reading many files in
+// order. A closed child can't be read again, so a later Seek or ReadAt
+// that needs it fails with ErrChildClosed, unless the child came from
+// NewLazy, or there is a WithChildFactory, in which case it is opened
+// again. The last child is left for Close.
+func WithCloseOnEOF(closeOnEOF bool) Option {
+	return func(o *options) {
+		o.closeOnEOF = closeOnEOF
+	}
+}
+
+// WithErrorHandler sets a function that is called when Read or Seek
+// gets an error from a child, or can't open a child from NewLazy. If fn
+// returns true, the child is skipped: it isn't read again, and reading
+// continues with the next child. If fn returns false, the error is
+// returned, as it is without a handler. ReadAt doesn't use the handler.
+//
+// The bytes of a skipped child that weren't read yet are left out of
+// what Read returns, so positions after them are still the same, but
+// Read returns fewer bytes than Size. With WithZeroFill, they are read
+// as zeros instead.
+func WithErrorHandler(fn func(childIdx int, err error) bool) Option {
+	return func(o *options) {
+		o.errorHandler = fn
+	}
+}
+
+// WithZeroFill makes the bytes of a child that WithErrorHandler skipped
+// read as zeros, so that Read returns Size bytes in all.
+func WithZeroFill() Option {
+	return func(o *options) {
+		o.zeroFill = true
+	}
+}
+
+// WithContext makes Read stop when ctx is done. Read checks ctx when it
+// starts and before it moves on to the next child, and returns
+// ctx.Err() without reading anything; if it had already read some
+// bytes, it returns them, and the next Read returns ctx.Err(). The
+// position is left just after the last byte returned, and Seek and
+// ReadAt still work. A Read of a child that is in progress isn't
+// interrupted.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithReadAhead makes Read start opening the next child from NewLazy in
+// a background goroutine when it is near the end of the current child,
+// so that the time it takes to open a child is spent while the current
+// one is still being read. If Read doesn't get to that child, because
+// of a Seek elsewhere, the child that was opened in the background is
+// closed. Children given to New are already open, so this has no effect
+// on them.
+func WithReadAhead() Option {
+	return func(o *options) {
+		o.readAhead = true
+	}
+}
+
+// WithReadAheadThreshold is WithReadAhead, with the next child opened
+// when there are fewer than n bytes left in the current one, instead of
+// DefaultReadAheadThreshold.
+func WithReadAheadThreshold(n int64) Option {
+	return func(o *options) {
+		o.readAhead = true
+		o.readAheadThreshold = n
+	}
+}
+
+// WithName sets the name that Stat gives, as for fs.FileInfo.Name.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithLazyMap makes MapChildren call its function for each child only
+// when the child is first read or seeked into, instead of right away.
+// The function must not change the size of the child, since the size
+// is needed before then.
+func WithLazyMap() Option {
+	return func(o *options) {
+		o.lazyMap = true
+	}
+}
+
+// WithMaxOpenFiles lets a ConcatFile keep up to n of its files open,
+// instead of only the one being read. Files that Read and ReadAt move
+// away from are kept open, so that going back to them doesn't open
+// them again; when there are n open, the one that was used least
+// recently is closed. The default, and any n < 1, is 1.
+//
+// For a MultiReadSeeker, it limits the children that can be opened
+// again, those from NewLazy, or all of them with WithChildFactory, to
+// n open at a time; when there are more, the ones used least recently
+// are closed, and they are opened again when they are needed. The
+// current child isn't closed, and while the children are shared, as
+// with Clone, none are. The default, and any n < 1, is no limit.
+func WithMaxOpenFiles(n int) Option {
+	return func(o *options) {
+		o.maxOpenFiles = n
+	}
+}
+
+// WithChildFactory sets a function that opens a child that was given
+// to New or Append again, after WithCloseOnEOF or WithMaxOpenFiles
+// closed it. i is the index that the child was given when it was
+// added; the child that fn returns must have the same bytes, and is
+// seeked to its start. Children from NewLazy are opened with their own
+// Open function instead.
+func WithChildFactory(fn func(i int) (ReadCloseSeeker, error)) Option {
+	return func(o *options) {
+		o.childFactory = fn
+	}
+}
+
+// An EmptyChildPolicy says what happens when a child whose size is 0 is
+// added, for WithEmptyChildPolicy.
+type EmptyChildPolicy int
+
+const (
+	// The child is skipped; it isn't counted as a child, but Close
+	// still closes it. This is the default.
+	SkipEmpty EmptyChildPolicy = iota
+
+	// Adding the child fails with ErrEmptyChild.
+	ErrorOnEmpty
+)
+
+// WithEmptyChildPolicy sets what New, Append, Insert, Replace, and
+// NewLazy do with a child whose size is 0. With ErrorOnEmpty, nothing
+// is added, and the children still belong to the caller, as when a
+// child can't be measured.
+func WithEmptyChildPolicy(policy EmptyChildPolicy) Option {
+	return func(o *options) {
+		o.emptyChildPolicy = policy
+	}
+}
+
+// WithObserver adds an Observer, which is told about each Read, Seek,
+// and child switch, as for metrics or tracing. It can be given more
+// than once; the observers are called in the order they were given.
+func WithObserver(observer Observer) Option {
+	return func(o *options) {
+		o.observers = append(o.observers, observer)
+	}
+}
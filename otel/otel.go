@@ -0,0 +1,96 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+
+// Package otel traces what MultiReadSeekers do, with OpenTelemetry
+// spans. It is separate so that only programs that use it depend on
+// go.opentelemetry.io/otel.
+package otel
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	multireadseeker "github.com/gilramir/concatfile"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type observer struct {
+	ctx    context.Context
+	tracer trace.Tracer
+
+	// The context of the Read in progress, by MultiReadSeeker, so that
+	// a child switch is in its span
+	reads sync.Map
+}
+
+// WithOTelTracer returns an Option that makes a MultiReadSeeker start a
+// span with tracer for each Read, named "MultiReadSeeker.Read", and
+// for each time Read moves on to the next child, named
+// "MultiReadSeeker.ChildSwitch", inside the span of the Read. The spans
+// have these attributes:
+//
+//	mrs.child_index     the child being read
+//	mrs.virtual_offset  the position in the MultiReadSeeker
+//	mrs.child_name      the name of the child, if it has one
+//	mrs.bytes_requested len(p), for Read
+//	mrs.bytes_returned  what Read returns, for Read
+//
+// The spans of Reads are root spans; see WithOTelTracerContext.
+func WithOTelTracer(tracer trace.Tracer) multireadseeker.Option {
+	return WithOTelTracerContext(context.Background(), tracer)
+}
+
+// WithOTelTracerContext is WithOTelTracer, with the spans of Reads
+// inside the span of ctx, as for the request that the MultiReadSeeker
+// is read for.
+func WithOTelTracerContext(ctx context.Context, tracer trace.Tracer) multireadseeker.Option {
+	return multireadseeker.WithObserver(&observer{
+		ctx:    ctx,
+		tracer: tracer,
+	})
+}
+
+// The attributes of the current child and position
+func childAttributes(mrseeker *multireadseeker.MultiReadSeeker, childIdx int) []attribute.KeyValue {
+	attributes := []attribute.KeyValue{
+		attribute.Int("mrs.child_index", childIdx),
+		attribute.Int64("mrs.virtual_offset", mrseeker.Tell()),
+	}
+	if names := mrseeker.ChildNames(); childIdx < len(names) && names[childIdx] != "" {
+		attributes = append(attributes, attribute.String("mrs.child_name", names[childIdx]))
+	}
+	return attributes
+}
+
+func (self *observer) StartRead(mrseeker *multireadseeker.MultiReadSeeker, requested int) func(n int, err error) {
+	attributes := append(childAttributes(mrseeker, mrseeker.CurrentChildIndex()),
+		attribute.Int("mrs.bytes_requested", requested))
+	ctx, span := self.tracer.Start(self.ctx, "MultiReadSeeker.Read",
+		trace.WithAttributes(attributes...))
+	self.reads.Store(mrseeker, ctx)
+
+	return func(n int, err error) {
+		self.reads.Delete(mrseeker)
+		span.SetAttributes(attribute.Int("mrs.bytes_returned", n))
+		if err != nil && err != io.EOF {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+func (self *observer) Seeked(mrseeker *multireadseeker.MultiReadSeeker, pos int64, err error) {
+}
+
+func (self *observer) ChildSwitched(mrseeker *multireadseeker.MultiReadSeeker, fromIndex, toIndex int) {
+	ctx := self.ctx
+	if readCtx, ok := self.reads.Load(mrseeker); ok {
+		ctx = readCtx.(context.Context)
+	}
+	_, span := self.tracer.Start(ctx, "MultiReadSeeker.ChildSwitch",
+		trace.WithAttributes(childAttributes(mrseeker, toIndex)...))
+	span.End()
+}
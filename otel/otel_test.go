@@ -0,0 +1,72 @@
+package otel
+
+import (
+	"io"
+	"io/ioutil"
+	"testing"
+
+	multireadseeker "github.com/gilramir/concatfile"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+// The attributes of a span, by key
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attributes := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	return attributes
+}
+
+func (s *MySuite) TestOTelTracer(c *C) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer("test")
+
+	traced, err := multireadseeker.NewWithOptions(
+		[]multireadseeker.Option{WithOTelTracer(tracer)},
+		multireadseeker.StringChild("ABC"), multireadseeker.StringChild("DEF"))
+	c.Assert(err, IsNil)
+	c.Assert(traced.SetChildName(0, "first"), IsNil)
+	c.Assert(traced.SetChildName(1, "second"), IsNil)
+
+	_, err = traced.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(traced, buf)
+	c.Assert(err, IsNil)
+
+	spans := recorder.Ended()
+	c.Assert(spans, HasLen, 2)
+	switchSpan, readSpan := spans[0], spans[1]
+	c.Check(readSpan.Name(), Equals, "MultiReadSeeker.Read")
+	attributes := spanAttributes(readSpan)
+	c.Check(attributes["mrs.child_index"].AsInt64(), Equals, int64(0))
+	c.Check(attributes["mrs.child_name"].AsString(), Equals, "first")
+	c.Check(attributes["mrs.virtual_offset"].AsInt64(), Equals, int64(1))
+	c.Check(attributes["mrs.bytes_requested"].AsInt64(), Equals, int64(4))
+	c.Check(attributes["mrs.bytes_returned"].AsInt64(), Equals, int64(4))
+
+	c.Check(switchSpan.Name(), Equals, "MultiReadSeeker.ChildSwitch")
+	c.Check(switchSpan.Parent().SpanID(), Equals, readSpan.SpanContext().SpanID())
+	attributes = spanAttributes(switchSpan)
+	c.Check(attributes["mrs.child_index"].AsInt64(), Equals, int64(1))
+	c.Check(attributes["mrs.child_name"].AsString(), Equals, "second")
+	c.Check(attributes["mrs.virtual_offset"].AsInt64(), Equals, int64(3))
+
+	_, err = ioutil.ReadAll(traced)
+	c.Assert(err, IsNil)
+	c.Check(len(recorder.Ended()) > 2, Equals, true)
+}
@@ -0,0 +1,24 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Looking at the next bytes without reading them.
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Peek returns the next n bytes, without moving the position, as to
+// look for the magic number of a file format. If fewer than n bytes
+// are left, it returns them with io.EOF. Peek reads with ReadAt, so
+// unlike with a bufio.Reader, the MultiReadSeeker can still be seeked.
+func (self *MultiReadSeeker) Peek(n int) ([]byte, error) {
+	if self.closed {
+		return nil, ErrClosedSeeker
+	}
+	if n < 0 {
+		return nil, errors.Errorf("Peek(%d); n must be >= 0", n)
+	}
+	p := make([]byte, n)
+	numRead, err := self.ReadAt(p, self.currentSuperPos)
+	return p[:numRead], err
+}
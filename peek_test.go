@@ -0,0 +1,33 @@
+package multireadseeker
+
+import (
+	"io"
+	"io/ioutil"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestPeek(c *C) {
+	mrseeker, err := New(newSeekOnlyChild("\x1f"), newSeekOnlyChild("\x8bABC"))
+	c.Assert(err, IsNil)
+	p, err := mrseeker.Peek(2)
+	c.Assert(err, IsNil)
+	c.Check(string(p), Equals, "\x1f\x8b")
+	c.Check(mrseeker.Tell(), Equals, int64(0))
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "\x1f\x8bABC")
+
+	_, err = mrseeker.Seek(3, io.SeekStart)
+	c.Assert(err, IsNil)
+	p, err = mrseeker.Peek(5)
+	c.Check(err, Equals, io.EOF)
+	c.Check(string(p), Equals, "BC")
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "BC")
+
+	p, err = mrseeker.Peek(0)
+	c.Assert(err, IsNil)
+	c.Check(p, HasLen, 0)
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Saving the position, to carry on reading after a restart.
+
+import (
+	"encoding/binary"
+	"encoding/gob"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// MarshalPosition returns the position, as from Tell, as 8 bytes, a
+// little-endian int64, to be saved as a checkpoint.
+func (self *MultiReadSeeker) MarshalPosition() ([]byte, error) {
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data, uint64(self.currentSuperPos))
+	return data, nil
+}
+
+// UnmarshalPosition seeks to the position in data, from
+// MarshalPosition, as after re-creating the MultiReadSeeker with the
+// same children.
+func (self *MultiReadSeeker) UnmarshalPosition(data []byte) error {
+	if len(data) != 8 {
+		return errors.Errorf("Position must be 8 bytes, not %d", len(data))
+	}
+	pos := int64(binary.LittleEndian.Uint64(data))
+	_, err := self.Seek(pos, io.SeekStart)
+	return err
+}
+
+// The version of the checkpoint format that SaveCheckpoint writes
+const checkpointVersion = 1
+
+// What SaveCheckpoint writes. Fields may be added; gob leaves out
+// fields that the reader doesn't know.
+type checkpoint struct {
+	Version    int
+	Position   int64
+	ChildSizes []int64
+	// The position of each child, or -1 if it isn't open, or failed
+	ChildPositions []int64
+}
+
+// SaveCheckpoint writes the position, and the sizes and positions of
+// the children, to w, with encoding/gob. RestoreCheckpoint puts them
+// back.
+func (self *MultiReadSeeker) SaveCheckpoint(w io.Writer) error {
+	cp := checkpoint{
+		Version:        checkpointVersion,
+		Position:       self.currentSuperPos,
+		ChildSizes:     self.ChildSizes(),
+		ChildPositions: make([]int64, len(self.children)),
+	}
+	for i, child := range self.children {
+		cp.ChildPositions[i] = -1
+		if child == nil || self.failed[i] {
+			continue
+		}
+		pos, err := child.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return &SeekError{ChildIndex: i, Whence: io.SeekCurrent, Err: err}
+		}
+		cp.ChildPositions[i] = pos
+	}
+	return errors.Wrap(gob.NewEncoder(w).Encode(&cp), "Writing checkpoint")
+}
+
+// RestoreCheckpoint reads a checkpoint from SaveCheckpoint, and seeks
+// the children that are open, and then the MultiReadSeeker, to the
+// positions in it. If the checkpoint can't be read, or the children
+// aren't the same number and sizes as when it was saved, the error is
+// ErrCheckpointMismatch, and nothing is seeked.
+func (self *MultiReadSeeker) RestoreCheckpoint(r io.Reader) error {
+	var cp checkpoint
+	err := gob.NewDecoder(r).Decode(&cp)
+	if err != nil {
+		return errors.Wrapf(ErrCheckpointMismatch, "Reading checkpoint: %v", err)
+	}
+	if cp.Version < 1 || cp.Version > checkpointVersion {
+		return errors.Wrapf(ErrCheckpointMismatch,
+			"Checkpoint version %d; must be from 1 to %d", cp.Version,
+			checkpointVersion)
+	}
+	if len(cp.ChildSizes) != len(self.children) ||
+		len(cp.ChildPositions) != len(self.children) {
+		return errors.Wrapf(ErrCheckpointMismatch,
+			"Checkpoint has %d children, not %d", len(cp.ChildSizes),
+			len(self.children))
+	}
+	for i, size := range self.ChildSizes() {
+		if cp.ChildSizes[i] != size {
+			return errors.Wrapf(ErrCheckpointMismatch,
+				"Child #%d (0-based) was %d bytes, not %d", i,
+				cp.ChildSizes[i], size)
+		}
+	}
+
+	for i, child := range self.children {
+		if cp.ChildPositions[i] < 0 || child == nil || self.failed[i] {
+			continue
+		}
+		err = seekChild(i, child, cp.ChildPositions[i])
+		if err != nil {
+			return err
+		}
+	}
+	_, err = self.Seek(cp.Position, io.SeekStart)
+	return err
+}
@@ -0,0 +1,70 @@
+package multireadseeker
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestMarshalPosition(c *C) {
+	mrseeker, err := NewFromStrings("ABC", "DEF")
+	c.Assert(err, IsNil)
+	_, err = mrseeker.Seek(4, io.SeekStart)
+	c.Assert(err, IsNil)
+	data, err := mrseeker.MarshalPosition()
+	c.Assert(err, IsNil)
+	c.Check(data, DeepEquals, []byte{4, 0, 0, 0, 0, 0, 0, 0})
+	c.Assert(mrseeker.Close(), IsNil)
+
+	// After a restart
+	mrseeker, err = NewFromStrings("ABC", "DEF")
+	c.Assert(err, IsNil)
+	err = mrseeker.UnmarshalPosition(data)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Tell(), Equals, int64(4))
+	rest, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(rest), Equals, "EF")
+
+	err = mrseeker.UnmarshalPosition([]byte{1, 2})
+	c.Check(err, ErrorMatches, "Position must be 8 bytes, not 2")
+	c.Check(mrseeker.Tell(), Equals, int64(6))
+}
+
+func (s *MySuite) TestCheckpoint(c *C) {
+	mrseeker, err := NewFromStrings("ABC", "DEF", "GHIJ")
+	c.Assert(err, IsNil)
+	_, err = mrseeker.Seek(4, io.SeekStart)
+	c.Assert(err, IsNil)
+	var saved bytes.Buffer
+	err = mrseeker.SaveCheckpoint(&saved)
+	c.Assert(err, IsNil)
+	c.Assert(mrseeker.Close(), IsNil)
+
+	// After a restart
+	mrseeker, err = NewFromStrings("ABC", "DEF", "GHIJ")
+	c.Assert(err, IsNil)
+	err = mrseeker.RestoreCheckpoint(bytes.NewReader(saved.Bytes()))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Tell(), Equals, int64(4))
+	c.Check(mrseeker.CurrentChildOffset(), Equals, int64(1))
+	rest, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(rest), Equals, "EFGHIJ")
+
+	// Different children
+	other, err := NewFromStrings("ABC", "DEFG", "HIJ")
+	c.Assert(err, IsNil)
+	err = other.RestoreCheckpoint(bytes.NewReader(saved.Bytes()))
+	c.Check(errors.Is(err, ErrCheckpointMismatch), Equals, true)
+	c.Check(other.Tell(), Equals, int64(0))
+	other, err = NewFromStrings("ABC", "DEF")
+	c.Assert(err, IsNil)
+	err = other.RestoreCheckpoint(bytes.NewReader(saved.Bytes()))
+	c.Check(errors.Is(err, ErrCheckpointMismatch), Equals, true)
+	err = other.RestoreCheckpoint(bytes.NewReader([]byte("garbage")))
+	c.Check(errors.Is(err, ErrCheckpointMismatch), Equals, true)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Opening the next child in the background, for WithReadAhead.
+
+// A child being opened in the background
+type readAhead struct {
+	index int
+
+	// Closed when child and err are set
+	done  chan struct{}
+	child ReadCloseSeeker
+	err   error
+}
+
+// If WithReadAhead was given, and Read is near the end of the current
+// child, start opening the next child, if it is from NewLazy and isn't
+// open yet.
+func (self *MultiReadSeeker) startReadAhead() {
+	if !self.options.readAhead || self.readAhead != nil {
+		return
+	}
+	next := self.currentSeekerNum + 1
+	if next >= len(self.children) || self.children[next] != nil ||
+		self.openers[next] == nil || self.failed[next] {
+		return
+	}
+	remaining := self.superPosEnd[self.currentSeekerNum] - self.currentSuperPos
+	if remaining >= self.options.readAheadThreshold {
+		return
+	}
+
+	ahead := &readAhead{
+		index: next,
+		done:  make(chan struct{}),
+	}
+	opener := self.openers[next]
+	go func() {
+		ahead.child, ahead.err = openLazyChild(next, opener)
+		close(ahead.done)
+	}()
+	self.readAhead = ahead
+}
+
+// Wait for the child that is being opened in the background, and
+// return it.
+func (self *MultiReadSeeker) takeReadAhead() (ReadCloseSeeker, error) {
+	ahead := self.readAhead
+	self.readAhead = nil
+	<-ahead.done
+	return ahead.child, ahead.err
+}
+
+// Give up on the child that is being opened in the background, if
+// there is one; when it has been opened, it is closed. Close waits for
+// that.
+func (self *MultiReadSeeker) cancelReadAhead() {
+	ahead := self.readAhead
+	if ahead == nil {
+		return
+	}
+	self.readAhead = nil
+	self.cancelledReadAhead.Add(1)
+	go func() {
+		defer self.cancelledReadAhead.Done()
+		<-ahead.done
+		if ahead.child != nil {
+			ahead.child.Close() // ignore any error
+		}
+	}()
+}
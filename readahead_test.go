@@ -0,0 +1,93 @@
+package multireadseeker
+
+import (
+	"io"
+	"io/ioutil"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// A LazyChild whose Open is reported on a channel
+func signalingLazyChild(data string, opened chan *seekOnlyChild) LazyChild {
+	return LazyChild{
+		Size: int64(len(data)),
+		Open: func() (ReadCloseSeeker, error) {
+			child := newSeekOnlyChild(data)
+			opened <- child
+			return child, nil
+		},
+	}
+}
+
+func (s *MySuite) TestReadAhead(c *C) {
+	opened := make(chan *seekOnlyChild, 10)
+	mrseeker, err := NewLazyWithOptions([]Option{WithReadAheadThreshold(2)},
+		[]LazyChild{
+			signalingLazyChild("ABCDE", opened),
+			signalingLazyChild("FGH", opened),
+		})
+	c.Assert(err, IsNil)
+
+	// Not near the end of the first child yet
+	buf := make([]byte, 2)
+	_, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	<-opened
+	c.Check(mrseeker.readAhead, IsNil)
+
+	// 1 byte left; the second child is opened in the background
+	_, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Assert(mrseeker.readAhead, NotNil)
+	select {
+	case <-opened:
+	case <-time.After(10 * time.Second):
+		c.Fatal("The second child wasn't opened")
+	}
+
+	// Read gets the child that was opened, and doesn't open another
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "EFGH")
+	c.Check(mrseeker.readAhead, IsNil)
+	c.Check(opened, HasLen, 0)
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestReadAheadCancelled(c *C) {
+	opened := make(chan *seekOnlyChild, 10)
+	mrseeker, err := NewLazyWithOptions([]Option{WithReadAheadThreshold(2)},
+		[]LazyChild{
+			signalingLazyChild("ABC", opened),
+			signalingLazyChild("DEF", opened),
+		})
+	c.Assert(err, IsNil)
+
+	buf := make([]byte, 2)
+	_, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	first := <-opened
+	c.Assert(mrseeker.readAhead, NotNil)
+
+	// Seeking back, before Read gets to the second child
+	_, err = mrseeker.Seek(0, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.readAhead, IsNil)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "AB")
+
+	// The cancelled child is closed, and the one that Read uses is
+	// opened again
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+	c.Check(first.closeCalls, Equals, 1)
+	c.Assert(opened, HasLen, 2)
+	cancelled := <-opened
+	c.Check(cancelled.closeCalls, Equals, 1)
+	second := <-opened
+	c.Check(second.closeCalls, Equals, 1)
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Reading until a buffer is full, or until the end, or discarding the rest.
+
+import (
+	"io"
+)
+
+// ReadFull reads exactly len(p) bytes, as io.ReadFull does, reading
+// from as many children as it takes. If fewer bytes are left, it reads
+// them and returns io.ErrUnexpectedEOF; at the end, it returns io.EOF.
+func (self *MultiReadSeeker) ReadFull(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	want := len(p)
+	// Since the sizes are known, a short read can be seen coming
+	if remaining := self.Remaining(); int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	numRead := 0
+	for numRead < len(p) {
+		n, err := self.Read(p[numRead:])
+		numRead += n
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return numRead, err
+		}
+	}
+	if numRead == want {
+		return numRead, nil
+	}
+	if numRead == 0 {
+		return 0, io.EOF
+	}
+	return numRead, io.ErrUnexpectedEOF
+}
+
+// ReadAll reads from the current position to the end, as io.ReadAll
+// does. Since the number of bytes left is known, the slice is allocated
+// once; it only grows if Read returns more than Remaining said, and it
+// can come back shorter, when WithErrorHandler skips part of a child.
+func (self *MultiReadSeeker) ReadAll() ([]byte, error) {
+	p := make([]byte, 0, self.Remaining())
+	for {
+		if len(p) == cap(p) {
+			if self.Remaining() <= 0 {
+				return p, nil
+			}
+			p = append(p, 0)[:len(p)]
+		}
+		n, err := self.Read(p[len(p):cap(p)])
+		p = p[:len(p)+n]
+		if err == io.EOF {
+			return p, nil
+		}
+		if err != nil {
+			return p, err
+		}
+	}
+}
+
+// Drain reads the rest of the bytes, from the current position to the
+// end, and discards them, returning how many there were. It reads with
+// Read, so, unlike a Seek to the end, it counts in Stats, calls the
+// OnChildSwitch and OnEOF hooks, and closes the children that
+// WithCloseOnEOF closes. It is io.Copy(io.Discard, self), which reads
+// into io.Discard's own buffers.
+func (self *MultiReadSeeker) Drain() (int64, error) {
+	return io.Copy(io.Discard, self)
+}
@@ -0,0 +1,111 @@
+package multireadseeker
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestReadFull(c *C) {
+	mrseeker, err := New(newSeekOnlyChild("AB"), newSeekOnlyChild("CDE"),
+		newSeekOnlyChild("F"), newSeekOnlyChild("GH"))
+	c.Assert(err, IsNil)
+
+	// Spanning exactly three children
+	buf := make([]byte, 6)
+	n, err := mrseeker.ReadFull(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "ABCDEF")
+	c.Check(mrseeker.CurrentChildIndex(), Equals, 2)
+
+	n, err = mrseeker.ReadFull(buf)
+	c.Check(err, Equals, io.ErrUnexpectedEOF)
+	c.Check(string(buf[:n]), Equals, "GH")
+	n, err = mrseeker.ReadFull(buf)
+	c.Check(err, Equals, io.EOF)
+	c.Check(n, Equals, 0)
+
+	_, err = mrseeker.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	n, err = mrseeker.ReadFull(buf[:5])
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "BCDEF")
+}
+
+func (s *MySuite) TestReadAll(c *C) {
+	mrseeker, err := NewFromStrings("ABC", "DEF")
+	c.Assert(err, IsNil)
+	_, err = mrseeker.Seek(2, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Remaining(), Equals, int64(4))
+	data, err := mrseeker.ReadAll()
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "CDEF")
+	c.Check(cap(data), Equals, 4)
+	c.Check(mrseeker.Tell(), Equals, mrseeker.Size())
+	c.Check(mrseeker.Remaining(), Equals, int64(0))
+
+	data, err = mrseeker.ReadAll()
+	c.Assert(err, IsNil)
+	c.Check(data, HasLen, 0)
+
+	// A child that fails
+	errBoom := errors.New("boom")
+	bad := newScriptedChild("XYZ")
+	bad.failAt = 1
+	bad.failErr = errBoom
+	mrseeker, err = New(newScriptedChild("ABC"), bad)
+	c.Assert(err, IsNil)
+	data, err = mrseeker.ReadAll()
+	c.Check(errors.Is(err, errBoom), Equals, true)
+	c.Check(string(data), Equals, "ABCX")
+
+	// The rest of a child that the error handler skips is left out
+	bad = newScriptedChild("XYZ")
+	bad.failAt = 1
+	bad.failErr = errBoom
+	var failedIdx []int
+	mrseeker, err = NewWithOptions([]Option{WithErrorHandler(
+		func(childIdx int, err error) bool {
+			failedIdx = append(failedIdx, childIdx)
+			return true
+		})},
+		newScriptedChild("ABC"), bad, newScriptedChild("DEF"))
+	c.Assert(err, IsNil)
+	data, err = mrseeker.ReadAll()
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCXDEF")
+	c.Check(failedIdx, DeepEquals, []int{1})
+	c.Check(mrseeker.Remaining(), Equals, int64(0))
+}
+
+func (s *MySuite) TestDrain(c *C) {
+	first := newSeekOnlyChild("ABC")
+	second := newSeekOnlyChild("DEFGH")
+	mrseeker, err := NewWithOptions([]Option{WithCloseOnEOF(true)},
+		first, second, newSeekOnlyChild("IJ"))
+	c.Assert(err, IsNil)
+	eofs := 0
+	mrseeker.OnEOF(func() { eofs++ })
+
+	_, err = mrseeker.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	n, err := mrseeker.Drain()
+	c.Assert(err, IsNil)
+	c.Check(n, Equals, int64(9))
+	c.Check(mrseeker.Tell(), Equals, int64(10))
+	c.Check(eofs, Equals, 1)
+	c.Check(first.closeCalls, Equals, 1)
+	c.Check(second.closeCalls, Equals, 1)
+	c.Check(mrseeker.Stats().TotalBytesRead, Equals, int64(9))
+
+	// At the end, there's nothing to drain
+	n, err = mrseeker.Drain()
+	c.Assert(err, IsNil)
+	c.Check(n, Equals, int64(0))
+
+	c.Assert(mrseeker.Close(), IsNil)
+	_, err = mrseeker.Drain()
+	c.Check(errors.Is(err, ErrClosedSeeker), Equals, true)
+}
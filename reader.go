@@ -0,0 +1,111 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// A Reader is a read position of its own over the children of a
+// MultiReadSeeker, from NewReader. It reads with the MultiReadSeeker's
+// ReadAt, so it doesn't change the MultiReadSeeker's position, or that
+// of any other Reader.
+type Reader struct {
+	parent *MultiReadSeeker
+	closed bool
+
+	currentSeekerNum int
+	currentSuperPos  int64
+}
+
+// NewReader returns a new Reader, at position 0. Readers can be used
+// from different goroutines at the same time only if all the children
+// are io.ReaderAt's that allow that, as *os.File does, and none of
+// them are children from NewLazy that haven't been opened yet; the
+// MultiReadSeeker itself must not be used at the same time.
+func (self *MultiReadSeeker) NewReader() *Reader {
+	return &Reader{
+		parent: self,
+	}
+}
+
+func (self *Reader) Read(p []byte) (int, error) {
+	if self.closed {
+		return 0, ErrClosedSeeker
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	n, err := self.parent.ReadAt(p, self.currentSuperPos)
+	self.setPos(self.currentSuperPos + int64(n))
+	if err == io.EOF && n > 0 {
+		// The next Read returns io.EOF
+		err = nil
+	}
+	return n, err
+}
+
+// Seek sets the position for the next Read, as MultiReadSeeker.Seek
+// does.
+func (self *Reader) Seek(offset int64, whence int) (int64, error) {
+	if self.closed {
+		return self.currentSuperPos, ErrClosedSeeker
+	}
+	var newSuperPos int64
+	switch whence {
+	case io.SeekStart:
+		newSuperPos = offset
+	case io.SeekCurrent:
+		newSuperPos = self.currentSuperPos + offset
+	case io.SeekEnd:
+		if offset > 0 {
+			return self.currentSuperPos, errors.Wrapf(ErrSeekPastEnd,
+				"Seek(%d, io.SeekEnd) with size %d; offset must be <= 0",
+				offset, self.parent.size)
+		}
+		newSuperPos = self.parent.size + offset
+	default:
+		return self.currentSuperPos,
+			errors.Errorf("Seek(offset, whence); whence must be 0, 1, or 2, not %d", whence)
+	}
+	if newSuperPos < 0 {
+		return self.currentSuperPos,
+			errors.Wrapf(ErrNegativeSeek, "Seek to %d", newSuperPos)
+	}
+	self.setPos(newSuperPos)
+	return self.currentSuperPos, nil
+}
+
+func (self *Reader) setPos(superPos int64) {
+	self.currentSuperPos = superPos
+	seekIndex := self.parent.findSeekIndex(superPos)
+	if seekIndex == seekImpossible {
+		seekIndex = len(self.parent.children) - 1
+		if seekIndex < 0 {
+			seekIndex = 0
+		}
+	}
+	self.currentSeekerNum = seekIndex
+}
+
+// Tell returns the current position.
+func (self *Reader) Tell() int64 {
+	return self.currentSuperPos
+}
+
+// CurrentChildIndex returns the index of the child that the current
+// position is in. At or beyond the end, it is the last child.
+func (self *Reader) CurrentChildIndex() int {
+	return self.currentSeekerNum
+}
+
+// Close ends the use of the Reader. It doesn't close the
+// MultiReadSeeker or its children.
+func (self *Reader) Close() error {
+	if self.closed {
+		return ErrAlreadyClosed
+	}
+	self.closed = true
+	return nil
+}
@@ -0,0 +1,89 @@
+package multireadseeker
+
+import (
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestNewReader(c *C) {
+	child1 := newSeekOnlyChild("ABC")
+	child2 := newSeekOnlyChild("DEF")
+	mrseeker, err := New(child1, child2)
+	c.Assert(err, IsNil)
+	buf := make([]byte, 2)
+	_, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+
+	reader1 := mrseeker.NewReader()
+	reader2 := mrseeker.NewReader()
+	pos, err := reader2.Seek(-2, io.SeekEnd)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(4))
+	c.Check(reader2.CurrentChildIndex(), Equals, 1)
+
+	n, err := reader1.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "AB")
+	n, err = reader2.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "EF")
+	_, err = reader2.Read(buf)
+	c.Check(err, Equals, io.EOF)
+
+	// None of them moved the others
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "CDEF")
+	data, err = ioutil.ReadAll(reader1)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "CDEF")
+	c.Check(reader1.Tell(), Equals, int64(6))
+
+	_, err = reader1.Seek(1, io.SeekEnd)
+	c.Check(errors.Is(err, ErrSeekPastEnd), Equals, true)
+	_, err = reader1.Seek(-1, io.SeekStart)
+	c.Check(errors.Is(err, ErrNegativeSeek), Equals, true)
+
+	// Closing a Reader doesn't close the children
+	err = reader1.Close()
+	c.Assert(err, IsNil)
+	_, err = reader1.Read(buf)
+	c.Check(errors.Is(err, ErrClosedSeeker), Equals, true)
+	c.Check(child1.closeCalls, Equals, 0)
+	_, err = reader2.Seek(0, io.SeekStart)
+	c.Assert(err, IsNil)
+	n, err = reader2.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "AB")
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+}
+
+// Run with -race; files allow concurrent ReadAt
+func (s *MySuite) TestNewReaderConcurrent(c *C) {
+	names := s.writeDataFiles(c, "newreader", "ABCDEFGH", "IJKLMNOP", "QRSTUVWXYZ")
+	mrseeker, err := NewFromFiles(names...)
+	c.Assert(err, IsNil)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reader := mrseeker.NewReader()
+			defer reader.Close()
+			data, err := ioutil.ReadAll(reader)
+			c.Check(err, IsNil)
+			c.Check(string(data), Equals, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+		}()
+	}
+	wg.Wait()
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Retrying reads that fail with transient errors.
+
+import (
+	"io"
+	"time"
+)
+
+// RetryPolicy says how RetryReadCloseSeeker retries a failed Read.
+type RetryPolicy struct {
+	// How many times to retry a Read; 0 means it isn't retried
+	MaxRetries int
+	// How long to wait before the first retry
+	InitialDelay time.Duration
+	// The delay is multiplied by this after each retry; a factor
+	// less than 1 is taken as 1
+	BackoffFactor float64
+	// Whether to retry after err. If nil, every error but io.EOF is
+	// retried.
+	ShouldRetry func(err error) bool
+}
+
+type retryChild struct {
+	child  ReadCloseSeeker
+	policy RetryPolicy
+	pos    int64
+
+	// The last Read returned the data that came with an error that is
+	// to be retried, but not the error; this Read is the retry
+	retryNext bool
+}
+
+// RetryReadCloseSeeker returns a child that retries a Read of r that
+// fails, as the policy says. Before each retry, r is sought back to
+// where the failed Read started. A Read that gets some data and an
+// error that is to be retried returns the data only, and the next Read
+// is the retry; an error that isn't to be retried is returned along
+// with the data.
+func RetryReadCloseSeeker(r ReadCloseSeeker, policy RetryPolicy) ReadCloseSeeker {
+	return &retryChild{
+		child:  r,
+		policy: policy,
+	}
+}
+
+func (self *retryChild) shouldRetry(err error) bool {
+	if err == io.EOF {
+		return false
+	}
+	if self.policy.ShouldRetry == nil {
+		return true
+	}
+	return self.policy.ShouldRetry(err)
+}
+
+func (self *retryChild) Read(p []byte) (int, error) {
+	delay := self.policy.InitialDelay
+	retries := 0
+	if self.retryNext {
+		self.retryNext = false
+		err := self.backOff(&delay)
+		if err != nil {
+			return 0, err
+		}
+		retries++
+	}
+	for ; ; retries++ {
+		n, err := self.child.Read(p)
+		self.pos += int64(n)
+		if err == nil {
+			return n, nil
+		}
+		if retries >= self.policy.MaxRetries || !self.shouldRetry(err) {
+			return n, err
+		}
+		if n > 0 {
+			// Return the data now, and retry with the next Read
+			self.retryNext = true
+			return n, nil
+		}
+
+		err = self.backOff(&delay)
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Wait before a retry, and make the next wait longer, as the policy
+// says; then seek the child back to where the failed Read started.
+func (self *retryChild) backOff(delay *time.Duration) error {
+	time.Sleep(*delay)
+	if self.policy.BackoffFactor > 1 {
+		*delay = time.Duration(float64(*delay) * self.policy.BackoffFactor)
+	}
+	_, err := self.child.Seek(self.pos, io.SeekStart)
+	return err
+}
+
+func (self *retryChild) Seek(offset int64, whence int) (int64, error) {
+	pos, err := self.child.Seek(offset, whence)
+	if err != nil {
+		return pos, err
+	}
+	self.pos = pos
+	self.retryNext = false
+	return pos, nil
+}
+
+func (self *retryChild) Close() error {
+	return self.child.Close()
+}
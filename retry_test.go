@@ -0,0 +1,135 @@
+package multireadseeker
+
+import (
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+var errTransient = errors.New("connection reset by peer")
+
+// A child whose first Reads fail. A failing Read loses partial bytes:
+// it moves the position past them, but doesn't return them, unless
+// withData is set.
+type flakyChild struct {
+	ReadCloseSeeker
+	failures  int
+	partial   int
+	withData  bool
+	readCalls int
+	seekCalls int
+}
+
+func (self *flakyChild) Read(p []byte) (int, error) {
+	self.readCalls++
+	if self.failures > 0 {
+		self.failures--
+		if self.withData {
+			n, _ := self.ReadCloseSeeker.Read(p[:self.partial])
+			return n, errTransient
+		}
+		self.ReadCloseSeeker.Read(make([]byte, self.partial))
+		return 0, errTransient
+	}
+	return self.ReadCloseSeeker.Read(p)
+}
+
+func (self *flakyChild) Seek(offset int64, whence int) (int64, error) {
+	self.seekCalls++
+	return self.ReadCloseSeeker.Seek(offset, whence)
+}
+
+func (s *MySuite) TestRetryReadCloseSeeker(c *C) {
+	policy := RetryPolicy{
+		MaxRetries:    3,
+		InitialDelay:  time.Millisecond,
+		BackoffFactor: 2,
+	}
+	flaky := &flakyChild{ReadCloseSeeker: StringChild("ABCDEF"), failures: 2}
+	mrseeker, err := New(StringChild("123"), RetryReadCloseSeeker(flaky, policy))
+	c.Assert(err, IsNil)
+	start := time.Now()
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "123ABCDEF")
+	// Waited 1ms, then 2ms
+	c.Check(time.Since(start) >= 3*time.Millisecond, Equals, true)
+	c.Check(flaky.failures, Equals, 0)
+	c.Assert(mrseeker.Close(), IsNil)
+
+	// Too many failures
+	flaky = &flakyChild{ReadCloseSeeker: StringChild("ABCDEF"), failures: 2}
+	child := RetryReadCloseSeeker(flaky, RetryPolicy{MaxRetries: 1})
+	buf := make([]byte, 4)
+	n, err := child.Read(buf)
+	c.Check(n, Equals, 0)
+	c.Check(err, Equals, errTransient)
+	c.Check(flaky.readCalls, Equals, 2)
+
+	// An error that isn't to be retried
+	flaky = &flakyChild{ReadCloseSeeker: StringChild("ABCDEF"), failures: 1}
+	child = RetryReadCloseSeeker(flaky, RetryPolicy{
+		MaxRetries:  3,
+		ShouldRetry: func(err error) bool { return err != errTransient },
+	})
+	_, err = child.Read(buf)
+	c.Check(err, Equals, errTransient)
+	c.Check(flaky.readCalls, Equals, 1)
+}
+
+func (s *MySuite) TestRetryReadCloseSeekerSeeksBack(c *C) {
+	flaky := &flakyChild{ReadCloseSeeker: StringChild("ABCDEF"),
+		failures: 2, partial: 2}
+	child := RetryReadCloseSeeker(flaky, RetryPolicy{MaxRetries: 2})
+	data, err := ioutil.ReadAll(child)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEF")
+	c.Check(flaky.seekCalls, Equals, 2)
+
+	pos, err := child.Seek(-2, io.SeekEnd)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(4))
+	flaky.failures = 1
+	data, err = ioutil.ReadAll(child)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "EF")
+}
+
+func (s *MySuite) TestRetryReadCloseSeekerShortRead(c *C) {
+	// A transient error that comes with data is retried by the next Read
+	flaky := &flakyChild{ReadCloseSeeker: StringChild("ABCDEF"),
+		failures: 1, partial: 2, withData: true}
+	child := RetryReadCloseSeeker(flaky, RetryPolicy{MaxRetries: 1})
+	buf := make([]byte, 4)
+	n, err := child.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "AB")
+	c.Check(flaky.seekCalls, Equals, 0)
+	data, err := ioutil.ReadAll(child)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "CDEF")
+	c.Check(flaky.seekCalls, Equals, 1)
+
+	// A permanent one is returned with the data
+	flaky = &flakyChild{ReadCloseSeeker: StringChild("ABCDEF"),
+		failures: 1, partial: 2, withData: true}
+	child = RetryReadCloseSeeker(flaky, RetryPolicy{
+		MaxRetries:  3,
+		ShouldRetry: func(err error) bool { return err != errTransient },
+	})
+	n, err = child.Read(buf)
+	c.Check(err, Equals, errTransient)
+	c.Check(string(buf[:n]), Equals, "AB")
+	c.Check(flaky.readCalls, Equals, 1)
+
+	// So is one that there are no retries left for
+	flaky = &flakyChild{ReadCloseSeeker: StringChild("ABCDEF"),
+		failures: 1, partial: 2, withData: true}
+	child = RetryReadCloseSeeker(flaky, RetryPolicy{})
+	n, err = child.Read(buf)
+	c.Check(err, Equals, errTransient)
+	c.Check(string(buf[:n]), Equals, "AB")
+}
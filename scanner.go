@@ -0,0 +1,17 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Reading lines, or other tokens, with bufio.Scanner.
+
+import (
+	"bufio"
+)
+
+// Scanner returns a bufio.Scanner that reads lines from the
+// MultiReadSeeker, from its position; use its Split method for
+// something other than bufio.ScanLines. The scanner reads ahead into
+// its own buffer, so after the MultiReadSeeker is seeked, what the
+// scanner returns is undefined.
+func (self *MultiReadSeeker) Scanner() *bufio.Scanner {
+	return bufio.NewScanner(self)
+}
@@ -0,0 +1,31 @@
+package multireadseeker
+
+import (
+	"bufio"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestScanner(c *C) {
+	// A line can span children
+	mrseeker, err := NewFromStrings("one\ntw", "o\n", "three")
+	c.Assert(err, IsNil)
+	scanner := mrseeker.Scanner()
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	c.Assert(scanner.Err(), IsNil)
+	c.Check(lines, DeepEquals, []string{"one", "two", "three"})
+
+	mrseeker, err = NewFromStrings("one tw", "o\nthree")
+	c.Assert(err, IsNil)
+	scanner = mrseeker.Scanner()
+	scanner.Split(bufio.ScanWords)
+	lines = nil
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	c.Assert(scanner.Err(), IsNil)
+	c.Check(lines, DeepEquals, []string{"one", "two", "three"})
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Stat, so that a MultiReadSeeker or a ConcatFile is an fs.File.
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var _ fs.File = (*MultiReadSeeker)(nil)
+var _ fs.File = (*ConcatFile)(nil)
+
+type fileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (self fileInfo) Name() string       { return self.name }
+func (self fileInfo) Size() int64        { return self.size }
+func (self fileInfo) Mode() fs.FileMode  { return 0444 }
+func (self fileInfo) ModTime() time.Time { return self.modTime }
+func (self fileInfo) IsDir() bool        { return false }
+func (self fileInfo) Sys() interface{}   { return nil }
+
+// Stat describes the MultiReadSeeker as a read-only file. The name is
+// from WithName, or DefaultName; the size is Size(), and the
+// modification time is when the MultiReadSeeker was created.
+func (self *MultiReadSeeker) Stat() (fs.FileInfo, error) {
+	if self.closed {
+		return nil, ErrClosedSeeker
+	}
+	name := self.options.name
+	if name == "" {
+		name = DefaultName
+	}
+	return fileInfo{
+		name:    name,
+		size:    self.size,
+		modTime: self.created,
+	}, nil
+}
+
+// Stat describes the ConcatFile as a read-only file, as for
+// http.ServeContent. The name is the base name of the first file; the
+// size is Size(), and the modification time is that of the file that
+// was modified most recently, which each file is stat'ed again to find.
+func (self *ConcatFile) Stat() (os.FileInfo, error) {
+	if !self.currentlyOpen {
+		return nil, os.ErrClosed
+	}
+	var modTime time.Time
+	for _, name := range self.filenames {
+		fileinfo, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		if fileinfo.ModTime().After(modTime) {
+			modTime = fileinfo.ModTime()
+		}
+	}
+	return fileInfo{
+		name:    filepath.Base(self.filenames[0]),
+		size:    self.superSize,
+		modTime: modTime,
+	}, nil
+}
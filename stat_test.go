@@ -0,0 +1,68 @@
+package multireadseeker
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestStat(c *C) {
+	before := time.Now()
+	mrseeker, err := NewFromStrings("ABC", "DEFG")
+	c.Assert(err, IsNil)
+	info, err := mrseeker.Stat()
+	c.Assert(err, IsNil)
+	c.Check(info.Name(), Equals, DefaultName)
+	c.Check(info.Size(), Equals, int64(7))
+	c.Check(info.Mode(), Equals, fs.FileMode(0444))
+	c.Check(info.IsDir(), Equals, false)
+	c.Check(info.ModTime().Before(before), Equals, false)
+	c.Check(info.ModTime().After(time.Now()), Equals, false)
+
+	// As an fs.File
+	var file fs.File = mrseeker
+	data, err := ioutil.ReadAll(file)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFG")
+	c.Assert(file.Close(), IsNil)
+	_, err = file.Stat()
+	c.Check(errors.Is(err, ErrClosedSeeker), Equals, true)
+
+	mrseeker, err = NewWithOptions([]Option{WithName("joined.log")},
+		StringChild("ABC"))
+	c.Assert(err, IsNil)
+	info, err = mrseeker.Stat()
+	c.Assert(err, IsNil)
+	c.Check(info.Name(), Equals, "joined.log")
+}
+
+func (s *MySuite) TestConcatFileStat(c *C) {
+	names := s.writeDataFiles(c, "cfstat", "ABC", "DEFG", "HI")
+	newest := time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i, name := range names {
+		modTime := newest.Add(-time.Duration(i) * time.Hour)
+		if i == 1 {
+			modTime = newest
+		}
+		c.Assert(os.Chtimes(name, modTime, modTime), IsNil)
+	}
+
+	cfile, err := Open(names)
+	c.Assert(err, IsNil)
+	info, err := cfile.Stat()
+	c.Assert(err, IsNil)
+	c.Check(info.Name(), Equals, filepath.Base(names[0]))
+	c.Check(info.Size(), Equals, int64(9))
+	c.Check(info.Mode(), Equals, fs.FileMode(0444))
+	c.Check(info.IsDir(), Equals, false)
+	c.Check(info.ModTime().Equal(newest), Equals, true)
+
+	c.Assert(cfile.Close(), IsNil)
+	_, err = cfile.Stat()
+	c.Check(errors.Is(err, os.ErrClosed), Equals, true)
+}
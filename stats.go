@@ -0,0 +1,116 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Counting the I/O done by a MultiReadSeeker.
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+)
+
+// ReadStats are the counters returned by Stats.
+type ReadStats struct {
+	// Bytes returned by Read, and calls to Read
+	TotalBytesRead int64
+	TotalReadCalls int64
+	// Calls to Seek
+	TotalSeekCalls int64
+	// Times that Read moved from one child to the next
+	ChildSwitches int64
+	// Bytes read from each child by Read, and seeks of each child,
+	// by child index
+	BytesPerChild []int64
+	SeeksPerChild []int64
+}
+
+type readStats struct {
+	mu    sync.Mutex
+	stats ReadStats
+}
+
+// Stats returns a copy of the counters. It can be called from another
+// goroutine while the MultiReadSeeker is being read. The counters are
+// by child index, so after the children change, as with Insert, the
+// counts for a child may be those of the child that used to be at its
+// index.
+func (self *MultiReadSeeker) Stats() ReadStats {
+	numChildren := self.NumChildren()
+	self.stats.mu.Lock()
+	defer self.stats.mu.Unlock()
+	stats := self.stats.stats
+	stats.BytesPerChild = make([]int64, numChildren)
+	copy(stats.BytesPerChild, self.stats.stats.BytesPerChild)
+	stats.SeeksPerChild = make([]int64, numChildren)
+	copy(stats.SeeksPerChild, self.stats.stats.SeeksPerChild)
+	return stats
+}
+
+// ResetStats sets all the counters to zero.
+func (self *MultiReadSeeker) ResetStats() {
+	self.stats.mu.Lock()
+	defer self.stats.mu.Unlock()
+	self.stats.stats = ReadStats{}
+}
+
+// WriteStatsTable writes the counters from Stats to w as a table.
+func (self *MultiReadSeeker) WriteStatsTable(w io.Writer) error {
+	stats := self.Stats()
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "Child\tBytes read\tSeeks\n")
+	var totalBytes, totalSeeks int64
+	for i := range stats.BytesPerChild {
+		fmt.Fprintf(tw, "%d\t%d\t%d\n", i, stats.BytesPerChild[i],
+			stats.SeeksPerChild[i])
+		totalBytes += stats.BytesPerChild[i]
+		totalSeeks += stats.SeeksPerChild[i]
+	}
+	fmt.Fprintf(tw, "Total\t%d\t%d\n", totalBytes, totalSeeks)
+	fmt.Fprintf(tw, "\nRead calls\t%d\n", stats.TotalReadCalls)
+	fmt.Fprintf(tw, "Bytes returned\t%d\n", stats.TotalBytesRead)
+	fmt.Fprintf(tw, "Seek calls\t%d\n", stats.TotalSeekCalls)
+	fmt.Fprintf(tw, "Child switches\t%d\n", stats.ChildSwitches)
+	return tw.Flush()
+}
+
+func (self *readStats) read(n int) {
+	self.mu.Lock()
+	self.stats.TotalReadCalls++
+	self.stats.TotalBytesRead += int64(n)
+	self.mu.Unlock()
+}
+
+func (self *readStats) seeked() {
+	self.mu.Lock()
+	self.stats.TotalSeekCalls++
+	self.mu.Unlock()
+}
+
+func (self *readStats) childSwitched() {
+	self.mu.Lock()
+	self.stats.ChildSwitches++
+	self.mu.Unlock()
+}
+
+// Grow a per-child slice to hold index i
+func growCounters(counters []int64, i int) []int64 {
+	for len(counters) <= i {
+		counters = append(counters, 0)
+	}
+	return counters
+}
+
+func (self *readStats) childRead(i int, n int) {
+	self.mu.Lock()
+	self.stats.BytesPerChild = growCounters(self.stats.BytesPerChild, i)
+	self.stats.BytesPerChild[i] += int64(n)
+	self.mu.Unlock()
+}
+
+func (self *readStats) childSeeked(i int) {
+	self.mu.Lock()
+	self.stats.SeeksPerChild = growCounters(self.stats.SeeksPerChild, i)
+	self.stats.SeeksPerChild[i]++
+	self.mu.Unlock()
+}
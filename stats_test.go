@@ -0,0 +1,61 @@
+package multireadseeker
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestStats(c *C) {
+	mrseeker, err := New(newSeekOnlyChild("ABC"), newSeekOnlyChild("DEFG"))
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Stats(), DeepEquals, ReadStats{
+		BytesPerChild: []int64{0, 0},
+		SeeksPerChild: []int64{0, 0},
+	})
+
+	buf := make([]byte, 5)
+	_, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	_, err = mrseeker.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "BCDEFG")
+
+	stats := mrseeker.Stats()
+	c.Check(stats.TotalBytesRead, Equals, int64(11))
+	c.Check(stats.TotalSeekCalls, Equals, int64(1))
+	c.Check(stats.ChildSwitches, Equals, int64(2))
+	c.Check(stats.BytesPerChild, DeepEquals, []int64{5, 6})
+	// The second child is seeked to its start each time Read moves to
+	// it, and the first child is seeked by Seek
+	c.Check(stats.SeeksPerChild, DeepEquals, []int64{1, 2})
+	c.Check(stats.TotalReadCalls > 2, Equals, true)
+
+	// Stats returns a copy
+	stats.BytesPerChild[0] = 100
+	c.Check(mrseeker.Stats().BytesPerChild[0], Equals, int64(5))
+
+	var out bytes.Buffer
+	err = mrseeker.WriteStatsTable(&out)
+	c.Assert(err, IsNil)
+	c.Check(out.String(), Matches, `Child  Bytes read  Seeks
+0      5           1
+1      6           2
+Total  11          3
+
+Read calls      \d+
+Bytes returned  11
+Seek calls      1
+Child switches  2
+`)
+
+	mrseeker.ResetStats()
+	c.Check(mrseeker.Stats(), DeepEquals, ReadStats{
+		BytesPerChild: []int64{0, 0},
+		SeeksPerChild: []int64{0, 0},
+	})
+}
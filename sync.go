@@ -0,0 +1,290 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+import (
+	"sync"
+)
+
+// SyncMultiReadSeeker wraps a MultiReadSeeker so that it can be used
+// from more than one goroutine. Methods that move the position or
+// change the children take a write lock; methods that only look, like
+// Tell and Size, take a read lock. ReadAt also takes the write lock,
+// because reading a child that isn't an io.ReaderAt moves the child,
+// and because it may open a child from NewLazy.
+//
+// The MultiReadSeeker must not be used directly once it is wrapped.
+type SyncMultiReadSeeker struct {
+	mu *sync.RWMutex
+	m  *MultiReadSeeker
+}
+
+// NewSync wraps mrseeker in a SyncMultiReadSeeker.
+func NewSync(mrseeker *MultiReadSeeker) *SyncMultiReadSeeker {
+	return &SyncMultiReadSeeker{
+		mu: &sync.RWMutex{},
+		m:  mrseeker,
+	}
+}
+
+// Wrap a MultiReadSeeker that shares children with self.m; it shares
+// the lock too.
+func (self *SyncMultiReadSeeker) share(mrseeker *MultiReadSeeker) *SyncMultiReadSeeker {
+	return &SyncMultiReadSeeker{
+		mu: self.mu,
+		m:  mrseeker,
+	}
+}
+
+func (self *SyncMultiReadSeeker) Read(p []byte) (int, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.m.Read(p)
+}
+
+func (self *SyncMultiReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.m.Seek(offset, whence)
+}
+
+func (self *SyncMultiReadSeeker) ReadAt(p []byte, off int64) (int, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.m.ReadAt(p, off)
+}
+
+func (self *SyncMultiReadSeeker) Close() error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.m.Close()
+}
+
+func (self *SyncMultiReadSeeker) Reset() error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.m.Reset()
+}
+
+func (self *SyncMultiReadSeeker) Tell() int64 {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+	return self.m.Tell()
+}
+
+func (self *SyncMultiReadSeeker) Size() int64 {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+	return self.m.Size()
+}
+
+func (self *SyncMultiReadSeeker) Remaining() int64 {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+	return self.m.Remaining()
+}
+
+func (self *SyncMultiReadSeeker) NumChildren() int {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+	return self.m.NumChildren()
+}
+
+// ChildAt takes the write lock, because it may open a child from
+// NewLazy. Using the child directly isn't synchronized.
+func (self *SyncMultiReadSeeker) ChildAt(i int) (ReadCloseSeeker, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.m.ChildAt(i)
+}
+
+func (self *SyncMultiReadSeeker) ChildSizeAt(i int) (int64, error) {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+	return self.m.ChildSizeAt(i)
+}
+
+func (self *SyncMultiReadSeeker) ChildStartPos(i int) (int64, error) {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+	return self.m.ChildStartPos(i)
+}
+
+func (self *SyncMultiReadSeeker) ChildEndPos(i int) (int64, error) {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+	return self.m.ChildEndPos(i)
+}
+
+func (self *SyncMultiReadSeeker) ChildSizes() []int64 {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+	return self.m.ChildSizes()
+}
+
+// Stats doesn't need the lock to be called while reading, but takes
+// the read lock, since it looks at the number of children.
+func (self *SyncMultiReadSeeker) Stats() ReadStats {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+	return self.m.Stats()
+}
+
+func (self *SyncMultiReadSeeker) ChildNames() []string {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+	return self.m.ChildNames()
+}
+
+// ChildByName takes the write lock, as ChildAt does.
+func (self *SyncMultiReadSeeker) ChildByName(name string) (int, ReadCloseSeeker, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.m.ChildByName(name)
+}
+
+func (self *SyncMultiReadSeeker) SetChildName(i int, name string) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.m.SetChildName(i, name)
+}
+
+func (self *SyncMultiReadSeeker) PositionToChild(pos int64) (int, int64, error) {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+	return self.m.PositionToChild(pos)
+}
+
+func (self *SyncMultiReadSeeker) CurrentChildIndex() int {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+	return self.m.CurrentChildIndex()
+}
+
+func (self *SyncMultiReadSeeker) CurrentChildOffset() int64 {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+	return self.m.CurrentChildOffset()
+}
+
+func (self *SyncMultiReadSeeker) Append(children ...ReadCloseSeeker) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.m.Append(children...)
+}
+
+func (self *SyncMultiReadSeeker) Insert(i int, child ReadCloseSeeker) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.m.Insert(i, child)
+}
+
+func (self *SyncMultiReadSeeker) Remove(i int) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.m.Remove(i)
+}
+
+func (self *SyncMultiReadSeeker) Replace(i int, newChild ReadCloseSeeker) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.m.Replace(i, newChild)
+}
+
+func (self *SyncMultiReadSeeker) Swap(i, j int) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.m.Swap(i, j)
+}
+
+func (self *SyncMultiReadSeeker) ReverseChildren() error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.m.ReverseChildren()
+}
+
+// SortChildren sorts the children, as MultiReadSeeker.SortChildren
+// does. less is called with the lock held.
+func (self *SyncMultiReadSeeker) SortChildren(less func(i, j int) bool) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.m.SortChildren(less)
+}
+
+func (self *SyncMultiReadSeeker) SortChildrenBySize() error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.m.SortChildrenBySize()
+}
+
+func (self *SyncMultiReadSeeker) SortChildrenBySizeDesc() error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.m.SortChildrenBySizeDesc()
+}
+
+// OnChildSwitch adds a hook, as MultiReadSeeker.OnChildSwitch does.
+// The hook is called with the lock held.
+func (self *SyncMultiReadSeeker) OnChildSwitch(fn func(fromIndex, toIndex int)) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.m.OnChildSwitch(fn)
+}
+
+// OnEOF adds a hook, as MultiReadSeeker.OnEOF does.
+func (self *SyncMultiReadSeeker) OnEOF(fn func()) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.m.OnEOF(fn)
+}
+
+// OnError adds a hook, as MultiReadSeeker.OnError does.
+func (self *SyncMultiReadSeeker) OnError(fn func(childIdx int, err error)) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.m.OnError(fn)
+}
+
+// Clone returns a SyncMultiReadSeeker for a clone of the
+// MultiReadSeeker. Since they share children, they share the lock too.
+func (self *SyncMultiReadSeeker) Clone() (*SyncMultiReadSeeker, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	clone, err := self.m.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return self.share(clone), nil
+}
+
+// Section is like Clone, for MultiReadSeeker.Section.
+func (self *SyncMultiReadSeeker) Section(start, end int64) (*SyncMultiReadSeeker, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	section, err := self.m.Section(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return self.share(section), nil
+}
+
+// Split is like Clone, for MultiReadSeeker.Split.
+func (self *SyncMultiReadSeeker) Split(pos int64) (*SyncMultiReadSeeker, *SyncMultiReadSeeker, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	before, after, err := self.m.Split(pos)
+	if err != nil {
+		return nil, nil, err
+	}
+	return self.share(before), self.share(after), nil
+}
+
+// FlattenNested is like Clone, for MultiReadSeeker.FlattenNested.
+func (self *SyncMultiReadSeeker) FlattenNested() (*SyncMultiReadSeeker, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	flat, err := self.m.FlattenNested()
+	if err != nil {
+		return nil, err
+	}
+	return self.share(flat), nil
+}
@@ -0,0 +1,54 @@
+package multireadseeker
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	. "gopkg.in/check.v1"
+)
+
+// Run with -race to check the locking
+func (s *MySuite) TestSyncConcurrentReadSeek(c *C) {
+	data := bytes.Repeat([]byte("0123456789"), 100)
+	mrseeker, err := NewFromBytes(data[:300], data[300:700], data[700:])
+	c.Assert(err, IsNil)
+	smrseeker := NewSync(mrseeker)
+	clone, err := smrseeker.Clone()
+	c.Assert(err, IsNil)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			buf := make([]byte, 7)
+			for i := 0; i < 200; i++ {
+				// Each Read follows a Seek in the same goroutine, but
+				// the other goroutines may get in between, so only
+				// check that the bytes are a run from the data
+				target := smrseeker
+				if g%2 == 1 {
+					target = clone
+				}
+				_, err := target.Seek(int64((g*131+i*17)%990), io.SeekStart)
+				c.Check(err, IsNil)
+				n, err := target.Read(buf)
+				c.Check(err, IsNil)
+				c.Check(bytes.Contains(data, buf[:n]), Equals, true)
+				_, err = target.ReadAt(buf, int64(i))
+				c.Check(err, IsNil)
+				c.Check(buf, DeepEquals, data[i:i+7])
+				c.Check(target.Size(), Equals, int64(1000))
+				target.Tell()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	c.Check(smrseeker.NumChildren(), Equals, 3)
+	err = clone.Close()
+	c.Assert(err, IsNil)
+	err = smrseeker.Close()
+	c.Assert(err, IsNil)
+}
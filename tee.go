@@ -0,0 +1,114 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Copying the bytes that are read to a writer.
+
+import (
+	"io"
+)
+
+// A TeeOption changes how TeeReadCloseSeeker works.
+type TeeOption func(*teeChild)
+
+// WithResetTeeOnSeek makes a Seek that moves the child call the
+// writer's Reset method, if it has one, as hash.Hash and
+// *bytes.Buffer do. Then the writer only has the bytes read since the
+// last Seek.
+func WithResetTeeOnSeek() TeeOption {
+	return func(self *teeChild) {
+		self.resetOnSeek = true
+	}
+}
+
+type teeChild struct {
+	child       ReadCloseSeeker
+	w           io.Writer
+	pos         int64
+	resetOnSeek bool
+}
+
+// TeeReadCloseSeeker returns a child that writes to w every byte that
+// is read from r, like io.TeeReader. An error from w is returned by
+// Read. If the child seeks, what is written to w isn't contiguous
+// anymore; see WithResetTeeOnSeek.
+func TeeReadCloseSeeker(r ReadCloseSeeker, w io.Writer, opts ...TeeOption) ReadCloseSeeker {
+	tee := &teeChild{
+		child: r,
+		w:     w,
+	}
+	for _, opt := range opts {
+		opt(tee)
+	}
+	return tee
+}
+
+func (self *teeChild) Read(p []byte) (int, error) {
+	n, err := self.child.Read(p)
+	self.pos += int64(n)
+	if n > 0 {
+		if n, err := self.w.Write(p[:n]); err != nil {
+			return n, err
+		}
+	}
+	return n, err
+}
+
+func (self *teeChild) Seek(offset int64, whence int) (int64, error) {
+	pos, err := self.child.Seek(offset, whence)
+	if err != nil {
+		return pos, err
+	}
+	if pos != self.pos && self.resetOnSeek {
+		if resetter, ok := self.w.(interface{ Reset() }); ok {
+			resetter.Reset()
+		}
+	}
+	self.pos = pos
+	return pos, nil
+}
+
+func (self *teeChild) Close() error {
+	return self.child.Close()
+}
+
+type teeReader struct {
+	parent *MultiReadSeeker
+	w      io.Writer
+	closed bool
+}
+
+// TeeRead returns an io.ReadCloser that reads from the
+// MultiReadSeeker, at its position, and writes to w the bytes that it
+// returns. If the MultiReadSeeker is seeked, what is written to w
+// isn't contiguous. Closing it doesn't close the MultiReadSeeker, but
+// it closes w if w is an io.Closer.
+func (self *MultiReadSeeker) TeeRead(w io.Writer) io.ReadCloser {
+	return &teeReader{
+		parent: self,
+		w:      w,
+	}
+}
+
+func (self *teeReader) Read(p []byte) (int, error) {
+	if self.closed {
+		return 0, ErrClosedSeeker
+	}
+	n, err := self.parent.Read(p)
+	if n > 0 {
+		if n, err := self.w.Write(p[:n]); err != nil {
+			return n, err
+		}
+	}
+	return n, err
+}
+
+func (self *teeReader) Close() error {
+	if self.closed {
+		return ErrAlreadyClosed
+	}
+	self.closed = true
+	if closer, ok := self.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package multireadseeker
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestTeeReadCloseSeeker(c *C) {
+	var out1, out2 bytes.Buffer
+	hash := sha256.New()
+	mrseeker, err := New(TeeReadCloseSeeker(StringChild("ABC"), &out1),
+		TeeReadCloseSeeker(StringChild("DEF"), io.MultiWriter(&out2, hash)))
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEF")
+	c.Check(out1.String(), Equals, "ABC")
+	c.Check(out2.String(), Equals, "DEF")
+	c.Check(fmt.Sprintf("%x", hash.Sum(nil)), Equals,
+		fmt.Sprintf("%x", sha256.Sum256([]byte("DEF"))))
+
+	// Reading again, after a Seek, writes the bytes again
+	_, err = mrseeker.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	_, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(out1.String(), Equals, "ABCBC")
+	c.Assert(mrseeker.Close(), IsNil)
+}
+
+func (s *MySuite) TestTeeReadCloseSeekerReset(c *C) {
+	var out bytes.Buffer
+	child := TeeReadCloseSeeker(StringChild("ABCDEF"), &out,
+		WithResetTeeOnSeek())
+	buf := make([]byte, 4)
+	_, err := child.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(out.String(), Equals, "ABCD")
+
+	// A Seek that doesn't move keeps what was written
+	_, err = child.Seek(0, io.SeekCurrent)
+	c.Assert(err, IsNil)
+	c.Check(out.String(), Equals, "ABCD")
+
+	_, err = child.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Check(out.String(), Equals, "")
+	data, err := ioutil.ReadAll(child)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "BCDEF")
+	c.Check(out.String(), Equals, "BCDEF")
+}
+
+type closingBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (self *closingBuffer) Close() error {
+	self.closed = true
+	return nil
+}
+
+func (s *MySuite) TestTeeRead(c *C) {
+	mrseeker, err := NewFromStrings("ABC", "DEF")
+	c.Assert(err, IsNil)
+	var out closingBuffer
+	reader := mrseeker.TeeRead(&out)
+
+	buf := make([]byte, 2)
+	n, err := reader.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "AB")
+	c.Check(out.String(), Equals, "AB")
+
+	// After a Seek, w isn't contiguous
+	_, err = mrseeker.Seek(4, io.SeekStart)
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "EF")
+	c.Check(out.String(), Equals, "ABEF")
+
+	c.Assert(reader.Close(), IsNil)
+	c.Check(out.closed, Equals, true)
+	_, err = mrseeker.Seek(0, io.SeekStart)
+	c.Assert(err, IsNil)
+	c.Assert(mrseeker.Close(), IsNil)
+}
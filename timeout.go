@@ -0,0 +1,127 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// Giving up on reads and seeks that take too long.
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+type timeoutChild struct {
+	child ReadCloseSeeker
+	d     time.Duration
+	pos   int64
+
+	// Closed when a Read or Seek that timed out finishes
+	pending chan struct{}
+	// Set after a timeout, since the child may not be at pos anymore
+	resync bool
+	// Read into this, not the caller's buffer, since a Read that
+	// times out goes on after it returns
+	buf []byte
+}
+
+// TimeoutReadCloseSeeker returns a child whose Read and Seek return
+// context.DeadlineExceeded if r takes longer than d. The Read or Seek
+// of r isn't cancelled, since an io.Reader can't be; it goes on in
+// the background until it finishes on its own. The next Read or Seek
+// waits for it, up to d, and then seeks r back to where it was before
+// the call that timed out, so no data is lost. Close waits for it
+// without a limit.
+func TimeoutReadCloseSeeker(r ReadCloseSeeker, d time.Duration) ReadCloseSeeker {
+	return &timeoutChild{
+		child: r,
+		d:     d,
+	}
+}
+
+// Run op in the background, waiting for it up to d. If a previous op
+// timed out, wait for it first, and seek the child back to pos.
+func (self *timeoutChild) run(op func()) error {
+	timer := time.NewTimer(self.d)
+	defer timer.Stop()
+	if self.pending != nil {
+		select {
+		case <-self.pending:
+			self.pending = nil
+		case <-timer.C:
+			return context.DeadlineExceeded
+		}
+	}
+
+	resync := self.resync
+	done := make(chan struct{})
+	var seekErr error
+	go func() {
+		defer close(done)
+		if resync {
+			_, seekErr = self.child.Seek(self.pos, io.SeekStart)
+			if seekErr != nil {
+				return
+			}
+		}
+		op()
+	}()
+	select {
+	case <-done:
+		if seekErr != nil {
+			return seekErr
+		}
+		self.resync = false
+		return nil
+	case <-timer.C:
+		self.pending = done
+		self.resync = true
+		self.buf = nil
+		return context.DeadlineExceeded
+	}
+}
+
+func (self *timeoutChild) Read(p []byte) (int, error) {
+	if len(self.buf) < len(p) {
+		self.buf = make([]byte, len(p))
+	}
+	buf := self.buf[:len(p)]
+	var n int
+	var readErr error
+	err := self.run(func() {
+		n, readErr = self.child.Read(buf)
+	})
+	if err != nil {
+		return 0, err
+	}
+	copy(p, buf[:n])
+	self.pos += int64(n)
+	return n, readErr
+}
+
+func (self *timeoutChild) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekCurrent {
+		// The child might not be at pos
+		offset += self.pos
+		whence = io.SeekStart
+	}
+	var pos int64
+	var seekErr error
+	err := self.run(func() {
+		pos, seekErr = self.child.Seek(offset, whence)
+	})
+	if err != nil {
+		return self.pos, err
+	}
+	if seekErr != nil {
+		return self.pos, seekErr
+	}
+	self.pos = pos
+	return self.pos, nil
+}
+
+func (self *timeoutChild) Close() error {
+	if self.pending != nil {
+		<-self.pending
+		self.pending = nil
+	}
+	return self.child.Close()
+}
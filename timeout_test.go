@@ -0,0 +1,62 @@
+package multireadseeker
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// A child whose next Read waits until release is closed, if block is set
+type blockingChild struct {
+	ReadCloseSeeker
+	block   bool
+	release chan struct{}
+}
+
+func (self *blockingChild) Read(p []byte) (int, error) {
+	if self.block {
+		self.block = false
+		<-self.release
+	}
+	return self.ReadCloseSeeker.Read(p)
+}
+
+func (s *MySuite) TestTimeoutReadCloseSeeker(c *C) {
+	blocking := &blockingChild{
+		ReadCloseSeeker: StringChild("ABCDEF"),
+		release:         make(chan struct{}),
+	}
+	child := TimeoutReadCloseSeeker(blocking, 10*time.Millisecond)
+	buf := make([]byte, 2)
+	n, err := child.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "AB")
+
+	blocking.block = true
+	n, err = child.Read(buf)
+	c.Check(n, Equals, 0)
+	c.Check(err, Equals, context.DeadlineExceeded)
+
+	// Still waiting for the Read that timed out
+	pos, err := child.Seek(0, io.SeekCurrent)
+	c.Check(err, Equals, context.DeadlineExceeded)
+	c.Check(pos, Equals, int64(2))
+
+	// Once it finishes, the child goes back to where it was, so the
+	// bytes that it read aren't lost
+	close(blocking.release)
+	data, err := ioutil.ReadAll(child)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "CDEF")
+
+	pos, err = child.Seek(-3, io.SeekCurrent)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(3))
+	data, err = ioutil.ReadAll(child)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "DEF")
+	c.Assert(child.Close(), IsNil)
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// MultiReadSeekers that read the children of another MultiReadSeeker.
+// They share the children, so they can't be used at the same time as
+// the original from different goroutines. Closing one of them doesn't
+// close the children; only the original does that.
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Clone returns a new MultiReadSeeker with the same children, and its
+// own position, starting at 0, like a copy of a bytes.Reader. Each of
+// the two can Read and Seek without changing the position of the
+// other. Children from NewLazy that haven't been opened yet are opened
+// now, so that the original owns them. Closing the clone doesn't close
+// the children, and the clone can't read a child once the original has
+// closed it.
+func (self *MultiReadSeeker) Clone() (*MultiReadSeeker, error) {
+	if self.closed {
+		return nil, ErrAlreadyClosed
+	}
+	// Shared first, so that WithMaxOpenFiles doesn't close any of them
+	if self.shared == nil {
+		self.shared = &sharedChildren{positioner: self}
+	}
+	for i := range self.children {
+		if self.failed[i] || self.openers[i] == nil {
+			continue
+		}
+		_, err := self.child(i)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	clone := &MultiReadSeeker{
+		initialized:   true,
+		created:       time.Now(),
+		children:      append([]ReadCloseSeeker(nil), self.children...),
+		superPosStart: append([]int64(nil), self.superPosStart...),
+		superPosEnd:   append([]int64(nil), self.superPosEnd...),
+		openers:       make([]func() (ReadCloseSeeker, error), len(self.children)),
+		failed:        append([]bool(nil), self.failed...),
+		names:         append([]string(nil), self.names...),
+		size:          self.size,
+		options:       self.options,
+		borrowed:      true,
+		shared:        self.shared,
+	}
+	return clone, nil
+}
+
+// Section returns a MultiReadSeeker that reads only the bytes in
+// [start, end) of this one, like io.NewSectionReader. Children that are
+// only partly in the range are wrapped so that only their part is read.
+// As with Clone, the children are shared, and children from NewLazy
+// that are in the range are opened now.
+func (self *MultiReadSeeker) Section(start, end int64) (*MultiReadSeeker, error) {
+	if self.closed {
+		return nil, ErrAlreadyClosed
+	}
+	if start < 0 || end > self.size || start >= end {
+		return nil, errors.Errorf(
+			"Section(%d, %d) with size %d; need 0 <= start < end <= size",
+			start, end, self.size)
+	}
+	return self.section(start, end)
+}
+
+func (self *MultiReadSeeker) section(start, end int64) (*MultiReadSeeker, error) {
+	// Shared first, so that WithMaxOpenFiles doesn't close any of them
+	if self.shared == nil {
+		self.shared = &sharedChildren{positioner: self}
+	}
+	view := &MultiReadSeeker{
+		initialized: true,
+		created:     time.Now(),
+		options:     self.options,
+		borrowed:    true,
+	}
+	for i := range self.children {
+		childStart := self.superPosStart[i]
+		childEnd := self.superPosEnd[i]
+		if childEnd <= start || childStart >= end {
+			continue
+		}
+
+		// The part of the child that is in the range
+		from := int64(0)
+		if start > childStart {
+			from = start - childStart
+		}
+		to := childEnd - childStart
+		if end < childEnd {
+			to = end - childStart
+		}
+
+		var child ReadCloseSeeker
+		if !self.failed[i] {
+			var err error
+			child, err = self.child(i)
+			if err != nil {
+				return nil, err
+			}
+			if from > 0 || to < childEnd-childStart {
+				child = OffsetChild(child, from, to)
+			}
+		}
+		view.appendChild(child, to-from, nil)
+		view.failed[len(view.failed)-1] = self.failed[i]
+		view.names[len(view.names)-1] = self.names[i]
+	}
+
+	view.shared = self.shared
+	return view, nil
+}
+
+// Split returns two MultiReadSeekers, one for the bytes before pos,
+// and one for the bytes from pos to the end, as with Section. A child
+// that pos is in the middle of is read in part by each. The original
+// doesn't change, and still owns the children.
+func (self *MultiReadSeeker) Split(pos int64) (*MultiReadSeeker, *MultiReadSeeker, error) {
+	if self.closed {
+		return nil, nil, ErrAlreadyClosed
+	}
+	if pos <= 0 || pos >= self.size {
+		return nil, nil, errors.Errorf("Split(%d) with size %d; need 0 < pos < size",
+			pos, self.size)
+	}
+	before, err := self.section(0, pos)
+	if err != nil {
+		return nil, nil, err
+	}
+	after, err := self.section(pos, self.size)
+	if err != nil {
+		return nil, nil, err
+	}
+	return before, after, nil
+}
+
+// FlattenNested returns a MultiReadSeeker whose children are the
+// children of this one, except that a child that is itself a
+// MultiReadSeeker is replaced by its own children, and so on for any
+// MultiReadSeekers that they have, so that each Read goes straight to
+// the child that has the bytes. As with Clone, the children are
+// shared, children from NewLazy are opened now, and the original
+// doesn't change. A MultiReadSeeker whose children are already shared
+// with another one, as with its own Clone, isn't replaced, since the
+// others have to know when its children are moved.
+func (self *MultiReadSeeker) FlattenNested() (*MultiReadSeeker, error) {
+	if self.closed {
+		return nil, ErrAlreadyClosed
+	}
+	if self.shared == nil {
+		self.shared = &sharedChildren{positioner: self}
+	}
+	flat := &MultiReadSeeker{
+		initialized: true,
+		created:     time.Now(),
+		options:     self.options,
+		borrowed:    true,
+		shared:      self.shared,
+	}
+	err := flat.appendFlattened(self)
+	if err != nil {
+		return nil, err
+	}
+	return flat, nil
+}
+
+// Append the children of mrseeker, and the children of any of them that
+// are MultiReadSeekers, to self.
+func (self *MultiReadSeeker) appendFlattened(mrseeker *MultiReadSeeker) error {
+	for i, child := range mrseeker.children {
+		size := mrseeker.superPosEnd[i] - mrseeker.superPosStart[i]
+		failed := mrseeker.failed[i]
+		if !failed && (child != nil || mrseeker.openers[i] != nil) {
+			var err error
+			child, err = mrseeker.child(i)
+			if err != nil {
+				return err
+			}
+		}
+
+		if nested, ok := child.(*MultiReadSeeker); ok && !failed {
+			// Reading the nested MultiReadSeeker's children directly
+			// moves them, so it has to know to put them back
+			if nested.shared == nil {
+				nested.shared = self.shared
+			}
+			// If they are shared with others, as with its own Clone, it
+			// is kept as one child, so that its Reads still put them back
+			// for the others.
+			if nested.shared == self.shared {
+				err := self.appendFlattened(nested)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		self.appendChild(child, size, nil)
+		self.failed[len(self.failed)-1] = failed
+		self.names[len(self.names)-1] = mrseeker.names[i]
+	}
+	return nil
+}
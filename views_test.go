@@ -0,0 +1,301 @@
+package multireadseeker
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestClone(c *C) {
+	children := []*seekOnlyChild{
+		newSeekOnlyChild("ABC"),
+		newSeekOnlyChild("DEF"),
+	}
+	mrseeker, err := New(children[0], children[1])
+	c.Assert(err, IsNil)
+	buf := make([]byte, 4)
+	_, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+
+	clone, err := mrseeker.Clone()
+	c.Assert(err, IsNil)
+	c.Check(clone.Tell(), Equals, int64(0))
+	c.Check(clone.Size(), Equals, int64(6))
+
+	// Reads of the two are interleaved on the same children
+	n, err := clone.Read(buf[:2])
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "AB")
+	n, err = mrseeker.Read(buf[:1])
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "E")
+	_, err = clone.Seek(4, io.SeekStart)
+	c.Assert(err, IsNil)
+	n, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "F")
+	n, err = clone.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "EF")
+
+	// A clone of the clone
+	clone2, err := clone.Clone()
+	c.Assert(err, IsNil)
+	n, err = clone2.ReadAt(buf[:3], 2)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "CDE")
+	data, err := ioutil.ReadAll(clone2)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEF")
+
+	// Closing a clone doesn't close the children
+	err = clone.Close()
+	c.Assert(err, IsNil)
+	err = clone2.Close()
+	c.Assert(err, IsNil)
+	for _, child := range children {
+		c.Check(child.closeCalls, Equals, 0)
+	}
+	_, err = mrseeker.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "BCDEF")
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+	for _, child := range children {
+		c.Check(child.closeCalls, Equals, 1)
+	}
+	_, err = mrseeker.Clone()
+	c.Check(errors.Is(err, ErrAlreadyClosed), Equals, true)
+}
+
+func (s *MySuite) TestCloneLazy(c *C) {
+	var opened []*seekOnlyChild
+	open := func(data string) func() (ReadCloseSeeker, error) {
+		return func() (ReadCloseSeeker, error) {
+			child := newSeekOnlyChild(data)
+			opened = append(opened, child)
+			return child, nil
+		}
+	}
+	mrseeker, err := NewLazy([]LazyChild{
+		{Size: 3, Open: open("ABC")},
+		{Size: 3, Open: open("DEF")},
+	})
+	c.Assert(err, IsNil)
+
+	// The original opens the children, and closes them
+	clone, err := mrseeker.Clone()
+	c.Assert(err, IsNil)
+	c.Check(opened, HasLen, 2)
+	data, err := ioutil.ReadAll(clone)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEF")
+	c.Check(opened, HasLen, 2)
+
+	err = clone.Close()
+	c.Assert(err, IsNil)
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+	for _, child := range opened {
+		c.Check(child.closeCalls, Equals, 1)
+	}
+}
+
+func (s *MySuite) TestSection(c *C) {
+	// Two children of 150 bytes each
+	data0 := strings.Repeat("a", 100) + strings.Repeat("b", 50)
+	data1 := strings.Repeat("c", 50) + strings.Repeat("d", 100)
+	mrseeker, err := New(newSeekOnlyChild(data0), newSeekOnlyChild(data1))
+	c.Assert(err, IsNil)
+
+	section, err := mrseeker.Section(100, 200)
+	c.Assert(err, IsNil)
+	c.Check(section.Size(), Equals, int64(100))
+	c.Check(section.ChildSizes(), DeepEquals, []int64{50, 50})
+	data, err := ioutil.ReadAll(section)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, strings.Repeat("b", 50)+strings.Repeat("c", 50))
+
+	// Seek and ReadAt stay in the section
+	pos, err := section.Seek(-1, io.SeekEnd)
+	c.Assert(err, IsNil)
+	c.Check(pos, Equals, int64(99))
+	buf := make([]byte, 10)
+	n, err := section.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "c")
+	n, err = section.ReadAt(buf, 45)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "bbbbbccccc")
+	n, err = section.ReadAt(buf, 95)
+	c.Check(err, Equals, io.EOF)
+	c.Check(string(buf[:n]), Equals, "ccccc")
+
+	// The original is unchanged
+	c.Check(mrseeker.Tell(), Equals, int64(0))
+	n, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "aaaaaaaaaa")
+
+	// Within one child
+	section, err = mrseeker.Section(10, 12)
+	c.Assert(err, IsNil)
+	c.Check(section.NumChildren(), Equals, 1)
+	data, err = ioutil.ReadAll(section)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "aa")
+	err = section.Close()
+	c.Assert(err, IsNil)
+
+	for _, bad := range [][2]int64{{-1, 10}, {0, 301}, {10, 10}, {20, 10}} {
+		_, err = mrseeker.Section(bad[0], bad[1])
+		c.Check(err, NotNil)
+	}
+}
+
+func (s *MySuite) TestSectionReaderAt(c *C) {
+	names := s.writeDataFiles(c, "sectionreaderat", "ABCDEF", "GHIJKL")
+	mrseeker, err := NewFromFiles(names...)
+	c.Assert(err, IsNil)
+	section, err := mrseeker.Section(4, 8)
+	c.Assert(err, IsNil)
+
+	// The partial children can still ReadAt
+	for i := 0; i < section.NumChildren(); i++ {
+		child, err := section.ChildAt(i)
+		c.Assert(err, IsNil)
+		_, ok := child.(io.ReaderAt)
+		c.Check(ok, Equals, true)
+	}
+	buf := make([]byte, 5)
+	n, err := section.ReadAt(buf, 0)
+	c.Check(err, Equals, io.EOF)
+	c.Check(string(buf[:n]), Equals, "EFGH")
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestSplit(c *C) {
+	mrseeker, err := New(newSeekOnlyChild("ABC"), newSeekOnlyChild("DEFG"),
+		newSeekOnlyChild("HI"))
+	c.Assert(err, IsNil)
+
+	before, after, err := mrseeker.Split(5)
+	c.Assert(err, IsNil)
+	c.Check(before.ChildSizes(), DeepEquals, []int64{3, 2})
+	c.Check(after.ChildSizes(), DeepEquals, []int64{2, 2})
+
+	// Reads of the two halves are interleaved
+	buf := make([]byte, 4)
+	n, err := before.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "ABCD")
+	n, err = after.Read(buf[:1])
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "F")
+	data, err := ioutil.ReadAll(before)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "E")
+	data, err = ioutil.ReadAll(after)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "GHI")
+
+	// At a child boundary
+	before, after, err = mrseeker.Split(3)
+	c.Assert(err, IsNil)
+	c.Check(before.ChildSizes(), DeepEquals, []int64{3})
+	c.Check(after.ChildSizes(), DeepEquals, []int64{4, 2})
+
+	_, _, err = mrseeker.Split(0)
+	c.Check(err, NotNil)
+	_, _, err = mrseeker.Split(9)
+	c.Check(err, NotNil)
+
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestFlattenNested(c *C) {
+	inner2, err := NewFromStrings("DE", "F")
+	c.Assert(err, IsNil)
+	inner1, err := New(newSeekOnlyChild("BC"), inner2)
+	c.Assert(err, IsNil)
+	mrseeker, err := New(newSeekOnlyChild("A"), inner1, newSeekOnlyChild("GH"))
+	c.Assert(err, IsNil)
+
+	flat, err := mrseeker.FlattenNested()
+	c.Assert(err, IsNil)
+	c.Check(flat.ChildSizes(), DeepEquals, []int64{1, 2, 2, 1, 2})
+	c.Check(mrseeker.ChildSizes(), DeepEquals, []int64{1, 5, 2})
+	data, err := ioutil.ReadAll(flat)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABCDEFGH")
+
+	// Reads of the original and the flattened one are interleaved
+	buf := make([]byte, 3)
+	_, err = mrseeker.Seek(2, io.SeekStart)
+	c.Assert(err, IsNil)
+	_, err = flat.Seek(3, io.SeekStart)
+	c.Assert(err, IsNil)
+	n, err := mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "CDE")
+	n, err = flat.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "DEF")
+	n, err = mrseeker.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "FGH")
+
+	err = flat.Close()
+	c.Assert(err, IsNil)
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestFlattenNestedShared(c *C) {
+	inner, err := NewFromStrings("BC", "DE")
+	c.Assert(err, IsNil)
+	innerClone, err := inner.Clone()
+	c.Assert(err, IsNil)
+	mrseeker, err := New(newSeekOnlyChild("A"), inner, newSeekOnlyChild("F"))
+	c.Assert(err, IsNil)
+
+	// The inner MultiReadSeeker has a clone, so it is kept whole
+	flat, err := mrseeker.FlattenNested()
+	c.Assert(err, IsNil)
+	c.Check(flat.ChildSizes(), DeepEquals, []int64{1, 4, 1})
+
+	// Reads of the clone and the flattened one are interleaved
+	buf := make([]byte, 2)
+	_, err = innerClone.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	n, err := innerClone.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "CD")
+	_, err = flat.Seek(1, io.SeekStart)
+	c.Assert(err, IsNil)
+	n, err = flat.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "BC")
+	n, err = innerClone.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "E")
+	n, err = flat.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "DE")
+
+	err = innerClone.Close()
+	c.Assert(err, IsNil)
+	err = flat.Close()
+	c.Assert(err, IsNil)
+	err = mrseeker.Close()
+	c.Assert(err, IsNil)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+package multireadseeker
+
+// XOR'ing the bytes of a child with a key.
+
+import (
+	"io"
+)
+
+type xorChild struct {
+	child ReadCloseSeeker
+	key   []byte
+	pos   int64
+}
+
+// A xorChild for a child that has ReadAt, so that it has ReadAt too
+type xorReaderAtChild struct {
+	*xorChild
+	readerAt io.ReaderAt
+}
+
+// XORMaskChild returns a child that XORs each byte of r with a byte of
+// key, repeating the key, as for data files that were obfuscated that
+// way. Byte i of r, from its start, is XOR'ed with key[i % len(key)],
+// however it is reached, so r must be at its start. The child has its
+// own copy of key. An empty key leaves the bytes as they are. Close
+// closes r.
+func XORMaskChild(r ReadCloseSeeker, key []byte) ReadCloseSeeker {
+	xor := &xorChild{
+		child: r,
+		key:   append([]byte(nil), key...),
+	}
+	if readerAt, ok := r.(io.ReaderAt); ok {
+		return xorReaderAtChild{xor, readerAt}
+	}
+	return xor
+}
+
+// XOR p, which was read from position pos, with the key
+func (self *xorChild) mask(p []byte, pos int64) {
+	if len(self.key) == 0 {
+		return
+	}
+	k := int(pos % int64(len(self.key)))
+	for i := range p {
+		p[i] ^= self.key[k]
+		k++
+		if k == len(self.key) {
+			k = 0
+		}
+	}
+}
+
+func (self *xorChild) Read(p []byte) (int, error) {
+	n, err := self.child.Read(p)
+	self.mask(p[:n], self.pos)
+	self.pos += int64(n)
+	return n, err
+}
+
+func (self *xorChild) Seek(offset int64, whence int) (int64, error) {
+	pos, err := self.child.Seek(offset, whence)
+	if err != nil {
+		return pos, err
+	}
+	self.pos = pos
+	return pos, nil
+}
+
+func (self *xorChild) Close() error {
+	return self.child.Close()
+}
+
+func (self xorReaderAtChild) ReadAt(p []byte, off int64) (int, error) {
+	n, err := self.readerAt.ReadAt(p, off)
+	self.mask(p[:n], off)
+	return n, err
+}
@@ -0,0 +1,49 @@
+package multireadseeker
+
+import (
+	"io"
+	"io/ioutil"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestXORMaskChild(c *C) {
+	plain := []byte("The quick brown fox")
+	key := []byte{0x01, 0x20, 0x7f}
+	masked := make([]byte, len(plain))
+	for i := range plain {
+		masked[i] = plain[i] ^ key[i%len(key)]
+	}
+
+	child := XORMaskChild(BytesChild(masked), key)
+	data, err := ioutil.ReadAll(child)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, string(plain))
+
+	// After a Seek, the key goes on from the right byte
+	buf := make([]byte, 5)
+	_, err = child.Seek(4, io.SeekStart)
+	c.Assert(err, IsNil)
+	_, err = io.ReadFull(child, buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf), Equals, "quick")
+	_, err = child.Seek(-3, io.SeekEnd)
+	c.Assert(err, IsNil)
+	_, err = io.ReadFull(child, buf[:3])
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:3]), Equals, "fox")
+	n, err := child.(io.ReaderAt).ReadAt(buf, 10)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, "brown")
+
+	// Masking twice gives back the bytes
+	child = XORMaskChild(XORMaskChild(StringChild("ABC"), key), key)
+	data, err = ioutil.ReadAll(child)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABC")
+
+	child = XORMaskChild(StringChild("ABC"), nil)
+	data, err = ioutil.ReadAll(child)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "ABC")
+}
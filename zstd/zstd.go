@@ -0,0 +1,390 @@
+// Copyright (c) 2017 by Gilbert Ramirez <gram@alumni.rice.edu>
+
+// Package zstd decompresses zstd-compressed children of a
+// MultiReadSeeker. It is separate so that only programs that use it
+// depend on github.com/klauspost/compress.
+package zstd
+
+import (
+	"encoding/binary"
+	"io"
+	"sort"
+
+	multireadseeker "github.com/gilramir/concatfile"
+	kzstd "github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+const (
+	// The first 4 bytes of a zstd frame
+	frameMagic = 0xFD2FB528
+
+	// Skippable frames, such as the one with a seek table, start
+	// with one of 16 magic numbers
+	skippableMagic     = 0x184D2A50
+	skippableMagicMask = 0xFFFFFFF0
+
+	// The seek table is in a skippable frame with this magic number,
+	// and ends with a footer with seekableMagic
+	seekTableMagic = 0x184D2A5E
+	seekableMagic  = 0x8F92EAB1
+	footerSize     = 9
+)
+
+// An Option changes how ZstdDecompressChild works.
+type Option func(*options)
+
+type options struct {
+	size int64
+}
+
+// WithKnownDecompressedSize gives the size of the decompressed data,
+// so that Seek(0, io.SeekEnd), as multireadseeker.New uses to find the
+// size of each child, doesn't decompress all of it to find out. A seek
+// table, if there is one, has the size already.
+func WithKnownDecompressedSize(n int64) Option {
+	return func(o *options) {
+		o.size = n
+	}
+}
+
+// ZstdDecompressChild returns a child that reads the decompressed data
+// of the zstd data in r, from r's current position to its end. If the
+// data is in the seekable format, with a seek table at the end, only
+// the frame that has the position being read is decompressed, so Seek
+// is cheap. Otherwise, as with multireadseeker.GzipDecompressChild, the
+// next Read after a Seek forwards decompresses up to the new position,
+// and after a Seek backwards, decompresses again from the start. It
+// returns an error if r doesn't start with a zstd frame. Close closes
+// r.
+func ZstdDecompressChild(r multireadseeker.ReadCloseSeeker, opts ...Option) (multireadseeker.ReadCloseSeeker, error) {
+	o := options{size: -1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	var magic [4]byte
+	_, err = io.ReadFull(r, magic[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "Reading zstd magic number")
+	}
+	magicNumber := binary.LittleEndian.Uint32(magic[:])
+	if magicNumber != frameMagic && magicNumber&skippableMagicMask != skippableMagic {
+		return nil, errors.Errorf("Not zstd data; magic number is %#x", magicNumber)
+	}
+
+	frames, err := readSeekTable(r, start)
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.Seek(start, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	// One goroutine; Read doesn't decompress ahead
+	decoder, err := kzstd.NewReader(nil, kzstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	if frames != nil {
+		return newSeekableChild(r, decoder, frames), nil
+	}
+	err = decoder.Reset(r)
+	if err != nil {
+		decoder.Close()
+		return nil, err
+	}
+	return &streamChild{
+		child:   r,
+		decoder: decoder,
+		start:   start,
+		size:    o.size,
+	}, nil
+}
+
+// A frame from the seek table
+type seekFrame struct {
+	compressedPos  int64
+	compressedSize int64
+	pos            int64
+	size           int64
+}
+
+// Read the seek table at the end of r, if there is one. The frames
+// start at start.
+func readSeekTable(r io.ReadSeeker, start int64) ([]seekFrame, error) {
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if end-start < 8+footerSize {
+		return nil, nil
+	}
+	var footer [footerSize]byte
+	_, err = r.Seek(end-footerSize, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.ReadFull(r, footer[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "Reading zstd seek table")
+	}
+	if binary.LittleEndian.Uint32(footer[5:]) != seekableMagic {
+		return nil, nil
+	}
+
+	numFrames := int64(binary.LittleEndian.Uint32(footer[:4]))
+	entrySize := int64(8)
+	if footer[4]&0x80 != 0 {
+		// Each entry has a checksum too
+		entrySize = 12
+	}
+	tableSize := 8 + numFrames*entrySize + footerSize
+	if end-start < tableSize {
+		return nil, errors.Errorf("zstd seek table of %d frames is larger than the data", numFrames)
+	}
+	table := make([]byte, tableSize-footerSize)
+	_, err = r.Seek(end-tableSize, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.ReadFull(r, table)
+	if err != nil {
+		return nil, errors.Wrap(err, "Reading zstd seek table")
+	}
+	if binary.LittleEndian.Uint32(table) != seekTableMagic ||
+		int64(binary.LittleEndian.Uint32(table[4:])) != tableSize-8 {
+		return nil, errors.New("zstd seek table has a bad frame header")
+	}
+
+	frames := make([]seekFrame, numFrames)
+	compressedPos, pos := start, int64(0)
+	for i := range frames {
+		entry := table[8+int64(i)*entrySize:]
+		frames[i] = seekFrame{
+			compressedPos:  compressedPos,
+			compressedSize: int64(binary.LittleEndian.Uint32(entry)),
+			pos:            pos,
+			size:           int64(binary.LittleEndian.Uint32(entry[4:])),
+		}
+		compressedPos += frames[i].compressedSize
+		pos += frames[i].size
+	}
+	if compressedPos != end-tableSize {
+		return nil, errors.Errorf("zstd seek table has %d compressed bytes, but there are %d",
+			compressedPos-start, end-tableSize-start)
+	}
+	return frames, nil
+}
+
+// A child whose data has a seek table
+type seekableChild struct {
+	child   multireadseeker.ReadCloseSeeker
+	decoder *kzstd.Decoder
+	frames  []seekFrame
+	size    int64
+	pos     int64
+
+	// The decompressed data of one frame; -1 for none
+	frameNum   int
+	frameData  []byte
+	compressed []byte
+}
+
+func newSeekableChild(r multireadseeker.ReadCloseSeeker, decoder *kzstd.Decoder,
+	frames []seekFrame) *seekableChild {
+	self := &seekableChild{
+		child:    r,
+		decoder:  decoder,
+		frames:   frames,
+		frameNum: -1,
+	}
+	if len(frames) > 0 {
+		last := frames[len(frames)-1]
+		self.size = last.pos + last.size
+	}
+	return self
+}
+
+func (self *seekableChild) Read(p []byte) (int, error) {
+	if self.pos >= self.size {
+		return 0, io.EOF
+	}
+	// The first frame that ends after pos; empty frames are passed over
+	i := sort.Search(len(self.frames), func(i int) bool {
+		return self.frames[i].pos+self.frames[i].size > self.pos
+	})
+	if i != self.frameNum {
+		err := self.decodeFrame(i)
+		if err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, self.frameData[self.pos-self.frames[i].pos:])
+	self.pos += int64(n)
+	return n, nil
+}
+
+// Decompress frame i into frameData
+func (self *seekableChild) decodeFrame(i int) error {
+	frame := self.frames[i]
+	self.frameNum = -1
+	_, err := self.child.Seek(frame.compressedPos, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	if int64(cap(self.compressed)) < frame.compressedSize {
+		self.compressed = make([]byte, frame.compressedSize)
+	}
+	self.compressed = self.compressed[:frame.compressedSize]
+	_, err = io.ReadFull(self.child, self.compressed)
+	if err != nil {
+		return errors.Wrapf(err, "Reading zstd frame %d", i)
+	}
+	self.frameData, err = self.decoder.DecodeAll(self.compressed, self.frameData[:0])
+	if err != nil {
+		return errors.Wrapf(err, "Decompressing zstd frame %d", i)
+	}
+	if int64(len(self.frameData)) != frame.size {
+		return errors.Errorf("zstd frame %d is %d bytes, but the seek table says %d",
+			i, len(self.frameData), frame.size)
+	}
+	self.frameNum = i
+	return nil
+}
+
+func (self *seekableChild) Seek(offset int64, whence int) (int64, error) {
+	newPos, err := seekPosition(self.pos, self.size, offset, whence)
+	if err != nil {
+		return self.pos, err
+	}
+	self.pos = newPos
+	return self.pos, nil
+}
+
+func (self *seekableChild) Close() error {
+	self.decoder.Close()
+	return self.child.Close()
+}
+
+// A child whose data doesn't have a seek table
+type streamChild struct {
+	child   multireadseeker.ReadCloseSeeker
+	decoder *kzstd.Decoder
+	start   int64
+
+	// The position in the decompressed data that the decoder is at,
+	// and the one that Seek moved to; Read catches up
+	pos    int64
+	seekTo int64
+
+	// -1 until it is known
+	size int64
+}
+
+func (self *streamChild) Read(p []byte) (int, error) {
+	if self.seekTo != self.pos {
+		err := self.catchUp()
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := self.read(p)
+	self.seekTo = self.pos
+	return n, err
+}
+
+// Read from the decoder, where it is
+func (self *streamChild) read(p []byte) (int, error) {
+	n, err := self.decoder.Read(p)
+	self.pos += int64(n)
+	if err == io.EOF {
+		self.size = self.pos
+	}
+	return n, err
+}
+
+// Decompress up to where Seek moved to
+func (self *streamChild) catchUp() error {
+	if self.seekTo < self.pos {
+		_, err := self.child.Seek(self.start, io.SeekStart)
+		if err != nil {
+			return err
+		}
+		err = self.decoder.Reset(self.child)
+		if err != nil {
+			return err
+		}
+		self.pos = 0
+	}
+	_, err := io.CopyN(io.Discard, streamReader{self}, self.seekTo-self.pos)
+	if err == io.EOF {
+		// Past the end, which wasn't known at the time of the Seek
+		self.seekTo = self.pos
+		err = nil
+	}
+	return err
+}
+
+// Reads from the decoder, where it is, as for io.Copy
+type streamReader struct {
+	*streamChild
+}
+
+func (self streamReader) Read(p []byte) (int, error) {
+	return self.read(p)
+}
+
+func (self *streamChild) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekEnd && self.size < 0 {
+		// Decompress the rest to find the end
+		err := self.catchUp()
+		if err == nil {
+			_, err = io.Copy(io.Discard, streamReader{self})
+		}
+		self.seekTo = self.pos
+		if err != nil {
+			return self.seekTo, err
+		}
+	}
+	newPos, err := seekPosition(self.seekTo, self.size, offset, whence)
+	if err != nil {
+		return self.seekTo, err
+	}
+	self.seekTo = newPos
+	return self.seekTo, nil
+}
+
+func (self *streamChild) Close() error {
+	self.decoder.Close()
+	return self.child.Close()
+}
+
+// The position that Seek moves to from pos, in data of size bytes;
+// size is -1 if it isn't known yet. Seeks past the end go to the end.
+func seekPosition(pos, size, offset int64, whence int) (int64, error) {
+	var newPos int64
+	switch whence {
+	case io.SeekStart:
+		newPos = offset
+	case io.SeekCurrent:
+		newPos = pos + offset
+	case io.SeekEnd:
+		newPos = size + offset
+	default:
+		return pos, errors.Errorf(
+			"Seek(offset, whence); whence must be 0, 1, or 2, not %d", whence)
+	}
+	if newPos < 0 {
+		return pos, errors.Wrapf(multireadseeker.ErrNegativeSeek, "Seek to %d", newPos)
+	}
+	if size >= 0 && newPos > size {
+		newPos = size
+	}
+	return newPos, nil
+}
@@ -0,0 +1,134 @@
+package zstd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	multireadseeker "github.com/gilramir/concatfile"
+	kzstd "github.com/klauspost/compress/zstd"
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+// Compress each of the parts as its own frame, with a seek table after
+// them if seekTable is true
+func zstdFrames(c *C, seekTable bool, parts ...string) []byte {
+	encoder, err := kzstd.NewWriter(nil)
+	c.Assert(err, IsNil)
+	defer encoder.Close()
+
+	var data, table []byte
+	for _, part := range parts {
+		frame := encoder.EncodeAll([]byte(part), nil)
+		data = append(data, frame...)
+		table = binary.LittleEndian.AppendUint32(table, uint32(len(frame)))
+		table = binary.LittleEndian.AppendUint32(table, uint32(len(part)))
+	}
+	if !seekTable {
+		return data
+	}
+	data = binary.LittleEndian.AppendUint32(data, seekTableMagic)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(table)+footerSize))
+	data = append(data, table...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(parts)))
+	data = append(data, 0)
+	return binary.LittleEndian.AppendUint32(data, seekableMagic)
+}
+
+func testParts() ([]string, string) {
+	var parts []string
+	for i := 0; i < 10; i++ {
+		parts = append(parts, strings.Repeat(fmt.Sprintf("%d", i), 100))
+	}
+	return parts, strings.Join(parts, "")
+}
+
+// Read 3 bytes at each position, in an order that goes back and forth
+func checkSeeks(c *C, child multireadseeker.ReadCloseSeeker, all string) {
+	buf := make([]byte, 3)
+	for _, pos := range []int64{950, 10, 398, 399, 0, 997} {
+		_, err := child.Seek(pos, io.SeekStart)
+		c.Assert(err, IsNil)
+		_, err = io.ReadFull(child, buf)
+		c.Assert(err, IsNil)
+		c.Check(string(buf), Equals, all[pos:pos+3])
+	}
+	end, err := child.Seek(5, io.SeekEnd)
+	c.Assert(err, IsNil)
+	c.Check(end, Equals, int64(len(all)))
+}
+
+func (s *MySuite) TestSeekable(c *C) {
+	parts, all := testParts()
+	child, err := ZstdDecompressChild(multireadseeker.BytesChild(zstdFrames(c, true, parts...)))
+	c.Assert(err, IsNil)
+	seekable, ok := child.(*seekableChild)
+	c.Assert(ok, Equals, true)
+	c.Check(seekable.frames, HasLen, 10)
+
+	mrseeker, err := multireadseeker.New(multireadseeker.StringChild("<"), child)
+	c.Assert(err, IsNil)
+	c.Check(mrseeker.Size(), Equals, int64(1001))
+	data, err := ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "<"+all)
+
+	// Only the frame with the position is decompressed
+	_, err = child.Seek(650, io.SeekStart)
+	c.Assert(err, IsNil)
+	buf := make([]byte, 100)
+	n, err := child.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:n]), Equals, all[650:700])
+	c.Check(seekable.frameNum, Equals, 6)
+
+	checkSeeks(c, child, all)
+	c.Assert(mrseeker.Close(), IsNil)
+}
+
+func (s *MySuite) TestStream(c *C) {
+	parts, all := testParts()
+	child, err := ZstdDecompressChild(multireadseeker.BytesChild(zstdFrames(c, false, parts...)))
+	c.Assert(err, IsNil)
+	_, ok := child.(*streamChild)
+	c.Assert(ok, Equals, true)
+	data, err := ioutil.ReadAll(child)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, all)
+	checkSeeks(c, child, all)
+	c.Assert(child.Close(), IsNil)
+
+	// With the size known, Seek(0, io.SeekEnd) doesn't decompress
+	r := multireadseeker.BytesChild(zstdFrames(c, false, parts...))
+	child, err = ZstdDecompressChild(r, WithKnownDecompressedSize(1000))
+	c.Assert(err, IsNil)
+	mrseeker, err := multireadseeker.New(child)
+	c.Assert(err, IsNil)
+	c.Check(child.(*streamChild).pos, Equals, int64(0))
+	data, err = ioutil.ReadAll(mrseeker)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, all)
+}
+
+func (s *MySuite) TestNotZstd(c *C) {
+	_, err := ZstdDecompressChild(multireadseeker.StringChild("not zstd"))
+	c.Check(err, ErrorMatches, "Not zstd data; magic number is 0x20746f6e")
+
+	// A seek table that doesn't match the frames
+	data := zstdFrames(c, true, "ABC", "DEF")
+	data = append(data[:4:4], data...)
+	_, err = ZstdDecompressChild(multireadseeker.BytesChild(data))
+	c.Check(err, ErrorMatches, "zstd seek table has .* compressed bytes, but there are .*")
+}